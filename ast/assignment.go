@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"bytes"
+	"node.go/token"
+)
+
+// ASSIGNMENT expression. Target is either an Identifier or an IndexExpression,
+// and Operator is '=' or a compound assignment such as '+='.
+type AssignExpression struct {
+	Token    token.Token // The assignment operator token
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+func (ae *AssignExpression) Pos() token.Position {
+	return ae.Target.Pos()
+}
+func (ae *AssignExpression) End() token.Position {
+	return ae.Value.End()
+}
+func (ae *AssignExpression) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(ae.Target.String())
+	buffer.WriteString(" ")
+	buffer.WriteString(ae.Operator)
+	buffer.WriteString(" ")
+	buffer.WriteString(ae.Value.String())
+
+	return buffer.String()
+}
@@ -11,6 +11,9 @@ type Node interface {
 	TokenLiteral() string // Token literal value
 
 	String() string
+
+	Pos() token.Position // position of the first character of the node
+	End() token.Position // position immediately after the node
 }
 
 type Statement interface {
@@ -25,7 +28,8 @@ type Expression interface {
 	expressionNode()
 }
 
-// LET statement
+// LET statement, which also stands for const and var declarations as told by
+// its token
 type LetStatement struct {
 	Token token.Token
 
@@ -38,6 +42,15 @@ func (ls *LetStatement) statementNode() {}
 func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
+func (ls *LetStatement) Pos() token.Position {
+	return ls.Token.Start
+}
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	return ls.Name.End()
+}
 func (ls *LetStatement) String() string {
 	var buffer bytes.Buffer
 
@@ -66,6 +79,15 @@ func (rs *ReturnStatement) statementNode() {}
 func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
+func (rs *ReturnStatement) Pos() token.Position {
+	return rs.Token.Start
+}
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
 func (rs *ReturnStatement) String() string {
 	var buffer bytes.Buffer
 
@@ -92,6 +114,18 @@ func (es *ExpressionStatement) statementNode() {}
 func (es *ExpressionStatement) TokenLiteral() string {
 	return es.Token.Literal
 }
+func (es *ExpressionStatement) Pos() token.Position {
+	if es.Expression != nil {
+		return es.Expression.Pos()
+	}
+	return es.Token.Start
+}
+func (es *ExpressionStatement) End() token.Position {
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+	return es.Token.End
+}
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -104,12 +138,20 @@ type BlockStatement struct {
 	Token token.Token
 
 	Statements []Statement
+
+	Rbrace token.Token
 }
 
 func (bs *BlockStatement) statementNode() {}
 func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
 }
+func (bs *BlockStatement) Pos() token.Position {
+	return bs.Token.Start
+}
+func (bs *BlockStatement) End() token.Position {
+	return bs.Rbrace.End
+}
 func (bs *BlockStatement) String() string {
 	var buffer bytes.Buffer
 	buffer.WriteString("{")
@@ -135,6 +177,12 @@ func (pe *PrefixExpression) expressionNode() {}
 func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
+func (pe *PrefixExpression) Pos() token.Position {
+	return pe.Token.Start
+}
+func (pe *PrefixExpression) End() token.Position {
+	return pe.Right.End()
+}
 func (pe *PrefixExpression) String() string {
 	var buffer bytes.Buffer
 
@@ -165,6 +213,12 @@ func (ie *InfixExpression) expressionNode() {}
 func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+func (ie *InfixExpression) Pos() token.Position {
+	return ie.Left.Pos()
+}
+func (ie *InfixExpression) End() token.Position {
+	return ie.Right.End()
+}
 func (ie *InfixExpression) String() string {
 	var buffer bytes.Buffer
 
@@ -189,6 +243,12 @@ func (i *Identifier) expressionNode() {}
 func (i *Identifier) TokenLiteral() string {
 	return i.Value
 }
+func (i *Identifier) Pos() token.Position {
+	return i.Token.Start
+}
+func (i *Identifier) End() token.Position {
+	return i.Token.End
+}
 func (i *Identifier) String() string {
 	return i.Value
 }
@@ -205,6 +265,15 @@ func (ie *IfExpression) expressionNode() {}
 func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+func (ie *IfExpression) Pos() token.Position {
+	return ie.Token.Start
+}
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	return ie.Consequence.End()
+}
 func (ie *IfExpression) String() string {
 	var buffer bytes.Buffer
 
@@ -226,15 +295,22 @@ func (ie *IfExpression) String() string {
 }
 
 type IndexExpression struct {
-	Token     token.Token
+	Token     token.Token // The '[' token
 	Container Expression
 	Index     Expression
+	Rbracket  token.Token
 }
 
 func (ie *IndexExpression) expressionNode() {}
 func (ie *IndexExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+func (ie *IndexExpression) Pos() token.Position {
+	return ie.Container.Pos()
+}
+func (ie *IndexExpression) End() token.Position {
+	return ie.Rbracket.End
+}
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -261,6 +337,12 @@ func (fl *FunctionLiteral) expressionNode() {}
 func (fl *FunctionLiteral) TokenLiteral() string {
 	return fl.Token.Literal
 }
+func (fl *FunctionLiteral) Pos() token.Position {
+	return fl.Token.Start
+}
+func (fl *FunctionLiteral) End() token.Position {
+	return fl.Body.End()
+}
 func (fl *FunctionLiteral) String() string {
 	var buffer bytes.Buffer
 
@@ -291,6 +373,12 @@ func (bl *BooleanLiteral) expressionNode() {}
 func (bl *BooleanLiteral) TokenLiteral() string {
 	return bl.Token.Literal
 }
+func (bl *BooleanLiteral) Pos() token.Position {
+	return bl.Token.Start
+}
+func (bl *BooleanLiteral) End() token.Position {
+	return bl.Token.End
+}
 func (bl *BooleanLiteral) String() string {
 	return bl.TokenLiteral()
 }
@@ -306,10 +394,37 @@ func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
+func (il *IntegerLiteral) Pos() token.Position {
+	return il.Token.Start
+}
+func (il *IntegerLiteral) End() token.Position {
+	return il.Token.End
+}
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+// Float literal
+type FloatLiteral struct {
+	Token token.Token
+
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+func (fl *FloatLiteral) Pos() token.Position {
+	return fl.Token.Start
+}
+func (fl *FloatLiteral) End() token.Position {
+	return fl.Token.End
+}
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
 // String literal
 type StringLiteral struct {
 	Token token.Token
@@ -321,23 +436,37 @@ func (sl *StringLiteral) expressionNode() {}
 func (sl *StringLiteral) TokenLiteral() string {
 	return sl.Token.Literal
 }
+func (sl *StringLiteral) Pos() token.Position {
+	return sl.Token.Start
+}
+func (sl *StringLiteral) End() token.Position {
+	return sl.Token.End
+}
 func (sl *StringLiteral) String() string {
 	return sl.Value
 }
 
 // Call expression
 type CallExpression struct {
-	Token token.Token
+	Token token.Token // The '(' token
 
 	Function Expression
 
 	Arguments []Expression
+
+	Rparen token.Token
 }
 
 func (ce *CallExpression) expressionNode() {}
 func (ce *CallExpression) TokenLiteral() string {
 	return ce.Token.Literal
 }
+func (ce *CallExpression) Pos() token.Position {
+	return ce.Function.Pos()
+}
+func (ce *CallExpression) End() token.Position {
+	return ce.Rparen.End
+}
 func (ce *CallExpression) String() string {
 	var buffer bytes.Buffer
 
@@ -360,12 +489,20 @@ type ArrayLiteral struct {
 	Token token.Token
 
 	Items []Expression
+
+	Rbracket token.Token
 }
 
 func (al *ArrayLiteral) expressionNode() {}
 func (al *ArrayLiteral) TokenLiteral() string {
 	return al.Token.Literal
 }
+func (al *ArrayLiteral) Pos() token.Position {
+	return al.Token.Start
+}
+func (al *ArrayLiteral) End() token.Position {
+	return al.Rbracket.End
+}
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 	var items []string
@@ -393,6 +530,18 @@ func (p *Program) TokenLiteral() string {
 		return ""
 	}
 }
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
 func (p *Program) String() string {
 	var buffer bytes.Buffer
 
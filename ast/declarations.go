@@ -0,0 +1,90 @@
+package ast
+
+import "node.go/token"
+
+// VarNames returns the names the statements declare with var, in order of
+// appearance and without duplicates. Function literals are not looked into as
+// their declarations belong to them.
+func VarNames(statements []Statement) []string {
+	collector := &varCollector{seen: make(map[string]bool)}
+	for _, statement := range statements {
+		collector.collect(statement)
+	}
+	return collector.names
+}
+
+type varCollector struct {
+	names []string
+	seen  map[string]bool
+}
+
+func (v *varCollector) collect(node Node) {
+	switch node := node.(type) {
+	case *LetStatement:
+		if node.Token.Type == token.VAR && !v.seen[node.Name.Value] {
+			v.seen[node.Name.Value] = true
+			v.names = append(v.names, node.Name.Value)
+		}
+		v.collectExpression(node.Value)
+	case *ReturnStatement:
+		v.collectExpression(node.ReturnValue)
+	case *ExpressionStatement:
+		v.collectExpression(node.Expression)
+	case *BlockStatement:
+		for _, statement := range node.Statements {
+			v.collect(statement)
+		}
+	case *WhileStatement:
+		v.collectExpression(node.Condition)
+		v.collect(node.Body)
+	case *ForInStatement:
+		v.collectExpression(node.Iterable)
+		v.collect(node.Body)
+	case *PrefixExpression:
+		v.collectExpression(node.Right)
+	case *InfixExpression:
+		v.collectExpression(node.Left)
+		v.collectExpression(node.Right)
+	case *LogicalExpression:
+		v.collectExpression(node.Left)
+		v.collectExpression(node.Right)
+	case *AssignExpression:
+		v.collectExpression(node.Target)
+		v.collectExpression(node.Value)
+	case *IfExpression:
+		v.collectExpression(node.Condition)
+		v.collect(node.Consequence)
+		if node.Alternative != nil {
+			v.collect(node.Alternative)
+		}
+	case *CallExpression:
+		v.collectExpression(node.Function)
+		v.collectExpressions(node.Arguments)
+	case *IndexExpression:
+		v.collectExpression(node.Container)
+		v.collectExpression(node.Index)
+	case *TemplateLiteral:
+		v.collectExpressions(node.Expressions)
+	case *ArrayLiteral:
+		v.collectExpressions(node.Items)
+	case *HashLiteral:
+		for _, pair := range node.Pairs {
+			v.collectExpression(pair.Key)
+			v.collectExpression(pair.Value)
+		}
+	}
+}
+
+// collectExpression skips the expressions missing from the tree, which are
+// nil interfaces
+func (v *varCollector) collectExpression(expression Expression) {
+	if expression != nil {
+		v.collect(expression)
+	}
+}
+
+func (v *varCollector) collectExpressions(expressions []Expression) {
+	for _, expression := range expressions {
+		v.collectExpression(expression)
+	}
+}
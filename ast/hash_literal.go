@@ -6,22 +6,36 @@ import (
 	"strings"
 )
 
+// HashPair is a key of a hash literal along with its value
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
 type HashLiteral struct {
 	Token token.Token
 
-	Pairs map[Expression]Expression
+	Pairs []HashPair // in source order
+
+	Rbrace token.Token
 }
 
 func (hl *HashLiteral) expressionNode() {}
 func (hl *HashLiteral) TokenLiteral() string {
 	return hl.Token.Literal
 }
+func (hl *HashLiteral) Pos() token.Position {
+	return hl.Token.Start
+}
+func (hl *HashLiteral) End() token.Position {
+	return hl.Rbrace.End
+}
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	var pairs []string
 
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+": "+value.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
 	}
 
 	out.WriteString("{")
@@ -0,0 +1,39 @@
+package ast
+
+import (
+	"bytes"
+	"node.go/token"
+)
+
+// LOGICAL expression: &&, || or ??. Unlike an InfixExpression, Right is only
+// evaluated when Left does not decide the result.
+type LogicalExpression struct {
+	Token    token.Token // The operator token
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+func (le *LogicalExpression) expressionNode() {}
+func (le *LogicalExpression) TokenLiteral() string {
+	return le.Token.Literal
+}
+func (le *LogicalExpression) Pos() token.Position {
+	return le.Left.Pos()
+}
+func (le *LogicalExpression) End() token.Position {
+	return le.Right.End()
+}
+func (le *LogicalExpression) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("(")
+	buffer.WriteString(le.Left.String())
+	buffer.WriteString(" ")
+	buffer.WriteString(le.Operator)
+	buffer.WriteString(" ")
+	buffer.WriteString(le.Right.String())
+	buffer.WriteString(")")
+
+	return buffer.String()
+}
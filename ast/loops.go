@@ -0,0 +1,111 @@
+package ast
+
+import (
+	"bytes"
+	"node.go/token"
+)
+
+// WHILE statement
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+func (ws *WhileStatement) Pos() token.Position {
+	return ws.Token.Start
+}
+func (ws *WhileStatement) End() token.Position {
+	return ws.Body.End()
+}
+func (ws *WhileStatement) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("while (")
+	buffer.WriteString(ws.Condition.String())
+	buffer.WriteString(") ")
+	buffer.WriteString(ws.Body.String())
+
+	return buffer.String()
+}
+
+// FOR IN statement. With a single variable, it takes the items of arrays, the
+// characters of strings and the keys of hashes. With two of them, Key takes the
+// indexes of arrays and strings or the keys of hashes, and Value the items,
+// characters or values.
+type ForInStatement struct {
+	Token    token.Token
+	Key      *Identifier // nil unless two variables are given
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode() {}
+func (fs *ForInStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+func (fs *ForInStatement) Pos() token.Position {
+	return fs.Token.Start
+}
+func (fs *ForInStatement) End() token.Position {
+	return fs.Body.End()
+}
+func (fs *ForInStatement) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("for (")
+	if fs.Key != nil {
+		buffer.WriteString(fs.Key.String())
+		buffer.WriteString(", ")
+	}
+	buffer.WriteString(fs.Value.String())
+	buffer.WriteString(" in ")
+	buffer.WriteString(fs.Iterable.String())
+	buffer.WriteString(") ")
+	buffer.WriteString(fs.Body.String())
+
+	return buffer.String()
+}
+
+// BREAK statement
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode() {}
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+func (bs *BreakStatement) Pos() token.Position {
+	return bs.Token.Start
+}
+func (bs *BreakStatement) End() token.Position {
+	return bs.Token.End
+}
+func (bs *BreakStatement) String() string {
+	return bs.Token.Literal + ";"
+}
+
+// CONTINUE statement
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+func (cs *ContinueStatement) Pos() token.Position {
+	return cs.Token.Start
+}
+func (cs *ContinueStatement) End() token.Position {
+	return cs.Token.End
+}
+func (cs *ContinueStatement) String() string {
+	return cs.Token.Literal + ";"
+}
@@ -0,0 +1,41 @@
+package ast
+
+import (
+	"bytes"
+	"node.go/token"
+)
+
+// TEMPLATE literal: "a ${x} b". Strings holds the text around the
+// expressions, so it always has one more item than Expressions.
+type TemplateLiteral struct {
+	Token       token.Token // The template head token
+	Strings     []string
+	Expressions []Expression
+	Tail        token.Token // The template tail token, ending the literal
+}
+
+func (tl *TemplateLiteral) expressionNode() {}
+func (tl *TemplateLiteral) TokenLiteral() string {
+	return tl.Token.Literal
+}
+func (tl *TemplateLiteral) Pos() token.Position {
+	return tl.Token.Start
+}
+func (tl *TemplateLiteral) End() token.Position {
+	return tl.Tail.End
+}
+func (tl *TemplateLiteral) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\"")
+	for index, expression := range tl.Expressions {
+		buffer.WriteString(tl.Strings[index])
+		buffer.WriteString("${")
+		buffer.WriteString(expression.String())
+		buffer.WriteString("}")
+	}
+	buffer.WriteString(tl.Strings[len(tl.Strings)-1])
+	buffer.WriteString("\"")
+
+	return buffer.String()
+}
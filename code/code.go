@@ -0,0 +1,276 @@
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"node.go/token"
+	"sort"
+)
+
+// Instructions is a sequence of encoded instructions: one byte holding the
+// opcode followed by its big endian operands
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpPop
+
+	OpTrue
+	OpFalse
+	OpNull
+
+	OpInfix  // operator index
+	OpPrefix // operator index
+
+	OpJump          // absolute offset
+	OpJumpNotTruthy // absolute offset, pops the condition
+
+	// Jumps of the logical operators, taken when the operand on top of the stack
+	// decides the result, which is then kept. Otherwise the operand is popped.
+	OpJumpFalsy   // absolute offset
+	OpJumpTruthy  // absolute offset
+	OpJumpNotNull // absolute offset
+
+	OpGetGlobal // global index
+	OpSetGlobal // global index
+	OpGetLocal  // local index
+	OpSetLocal  // local index
+	OpGetFree   // free variable index
+
+	// Assignments leave the assigned value on the stack. Unlike OpSetGlobal,
+	// OpAssignGlobal requires the global to have been defined.
+	OpAssignGlobal // global index
+	OpAssignLocal  // local index
+	OpAssignFree   // free variable index
+	OpSetIndex     // 0 for '=', 1 + operator index for compound assignments
+
+	OpDeclareGlobal // global index, sets the global to null unless defined, as var does
+
+	OpArray // number of items
+	OpHash  // number of keys plus values
+	OpIndex
+
+	OpTemplate // number of strings plus expressions, joined by their display form
+
+	OpCall // number of arguments
+	OpReturnValue
+
+	OpClosure // constant index, number of free variables
+
+	// Closures share the variables they capture with the enclosing function, by
+	// means of cells pushed by the following instructions
+	OpCaptureLocal // local index
+	OpCaptureFree  // free variable index
+
+	OpIterator // number of loop variables, replaces the iterable with an iterator
+	OpIterNext // offset to jump to once the iterator on top of the stack is done
+
+	OpError // constant index of the message of the runtime error to raise
+)
+
+// Definition describes an opcode: its name and the width in bytes of each of
+// its operands
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant:      {"OpConstant", []int{2}},
+	OpPop:           {"OpPop", []int{}},
+	OpTrue:          {"OpTrue", []int{}},
+	OpFalse:         {"OpFalse", []int{}},
+	OpNull:          {"OpNull", []int{}},
+	OpInfix:         {"OpInfix", []int{1}},
+	OpPrefix:        {"OpPrefix", []int{1}},
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+	OpJumpFalsy:     {"OpJumpFalsy", []int{2}},
+	OpJumpTruthy:    {"OpJumpTruthy", []int{2}},
+	OpJumpNotNull:   {"OpJumpNotNull", []int{2}},
+	OpGetGlobal:     {"OpGetGlobal", []int{2}},
+	OpSetGlobal:     {"OpSetGlobal", []int{2}},
+	OpDeclareGlobal: {"OpDeclareGlobal", []int{2}},
+	OpGetLocal:      {"OpGetLocal", []int{1}},
+	OpSetLocal:      {"OpSetLocal", []int{1}},
+	OpGetFree:       {"OpGetFree", []int{1}},
+	OpAssignGlobal:  {"OpAssignGlobal", []int{2}},
+	OpAssignLocal:   {"OpAssignLocal", []int{1}},
+	OpAssignFree:    {"OpAssignFree", []int{1}},
+	OpSetIndex:      {"OpSetIndex", []int{1}},
+	OpArray:         {"OpArray", []int{2}},
+	OpHash:          {"OpHash", []int{2}},
+	OpIndex:         {"OpIndex", []int{}},
+	OpTemplate:      {"OpTemplate", []int{2}},
+	OpCall:          {"OpCall", []int{1}},
+	OpReturnValue:   {"OpReturnValue", []int{}},
+	OpClosure:       {"OpClosure", []int{2, 1}},
+	OpCaptureLocal:  {"OpCaptureLocal", []int{1}},
+	OpCaptureFree:   {"OpCaptureFree", []int{1}},
+	OpIterator:      {"OpIterator", []int{1}},
+	OpIterNext:      {"OpIterNext", []int{2}},
+	OpError:         {"OpError", []int{2}},
+}
+
+func Lookup(op byte) (*Definition, error) {
+	definition, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return definition, nil
+}
+
+// Make encodes an instruction. Unknown opcodes result in an empty instruction.
+func Make(op Opcode, operands ...int) []byte {
+	definition, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, width := range definition.OperandWidths {
+		length += width
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for index, operand := range operands {
+		width := definition.OperandWidths[index]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands decodes the operands of an instruction, returning them along
+// with the number of bytes read
+func ReadOperands(definition *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(definition.OperandWidths))
+	offset := 0
+
+	for index, width := range definition.OperandWidths {
+		switch width {
+		case 2:
+			operands[index] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[index] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint8(ins Instructions) uint8 {
+	return ins[0]
+}
+
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	index := 0
+	for index < len(ins) {
+		definition, err := Lookup(ins[index])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			index++
+			continue
+		}
+
+		operands, read := ReadOperands(definition, ins[index+1:])
+		fmt.Fprintf(&out, "%04d %s\n", index, ins.formatInstruction(definition, operands))
+		index += 1 + read
+	}
+
+	return out.String()
+}
+
+func (ins Instructions) formatInstruction(definition *Definition, operands []int) string {
+	operandCount := len(definition.OperandWidths)
+
+	if len(operands) != operandCount {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n",
+			len(operands), operandCount)
+	}
+
+	switch operandCount {
+	case 0:
+		return definition.Name
+	case 1:
+		return fmt.Sprintf("%s %d", definition.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", definition.Name, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operandCount for %s\n", definition.Name)
+}
+
+// Operators lists the prefix and infix operators understood by OpPrefix and
+// OpInfix, which refer to them by index
+var Operators = []string{
+	token.PLUS,
+	token.MINUS,
+	token.ASTERISK,
+	token.SLASH,
+	token.PERCENT,
+	token.POWER,
+	token.EQ,
+	token.NOT_EQ,
+	token.LT,
+	token.GT,
+	token.LTE,
+	token.GTE,
+	token.BANG,
+	token.BIT_AND,
+	token.BIT_OR,
+	token.SHIFT_LEFT,
+	token.SHIFT_RIGHT,
+	token.BIT_NOT,
+}
+
+// OperatorIndex returns the operand identifying the operator
+func OperatorIndex(operator string) (int, bool) {
+	for index, candidate := range Operators {
+		if candidate == operator {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// SourcePosition maps the instruction at Offset back to the code it has been
+// compiled from
+type SourcePosition struct {
+	Offset int
+	Pos    token.Position
+}
+
+// Positions is a source map sorted by offset
+type Positions []SourcePosition
+
+// Lookup returns the position of the closest mapped instruction at or before
+// the given offset
+func (p Positions) Lookup(offset int) token.Position {
+	index := sort.Search(len(p), func(i int) bool {
+		return p[i].Offset > offset
+	})
+	if index == 0 {
+		return token.Position{}
+	}
+	return p[index-1].Pos
+}
@@ -0,0 +1,111 @@
+package code
+
+import (
+	"node.go/token"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+		{OpPop, []int{}, []byte{byte(OpPop)}},
+	}
+
+	for _, test := range tests {
+		instruction := Make(test.op, test.operands...)
+		if len(instruction) != len(test.expected) {
+			t.Fatalf("instruction has wrong length. Expected %d. Got %d",
+				len(test.expected), len(instruction))
+		}
+		for index, expected := range test.expected {
+			if instruction[index] != expected {
+				t.Fatalf("wrong byte at pos %d. Expected %d. Got %d",
+					index, expected, instruction[index])
+			}
+		}
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
+		{OpClosure, []int{65535, 255}, 3},
+	}
+
+	for _, test := range tests {
+		instruction := Make(test.op, test.operands...)
+		definition, err := Lookup(byte(test.op))
+		if err != nil {
+			t.Fatalf("definition not found: %q", err)
+		}
+		operandsRead, read := ReadOperands(definition, instruction[1:])
+		if read != test.bytesRead {
+			t.Fatalf("wrong number of bytes read. Expected %d. Got %d", test.bytesRead, read)
+		}
+		for index, expected := range test.operands {
+			if operandsRead[index] != expected {
+				t.Errorf("wrong operand. Expected %d. Got %d", expected, operandsRead[index])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpInfix, 0),
+		Make(OpGetLocal, 1),
+		Make(OpConstant, 2),
+		Make(OpConstant, 65535),
+		Make(OpClosure, 65535, 255),
+	}
+
+	expected := `0000 OpInfix 0
+0002 OpGetLocal 1
+0004 OpConstant 2
+0007 OpConstant 65535
+0010 OpClosure 65535 255
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+
+	if concatted.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nExpected %q\nGot %q", expected, concatted.String())
+	}
+}
+
+func TestPositionsLookup(t *testing.T) {
+	positions := Positions{
+		{Offset: 2, Pos: token.Position{Line: 1, Column: 3}},
+		{Offset: 7, Pos: token.Position{Line: 2, Column: 1}},
+	}
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "-"},
+		{2, "1:3"},
+		{6, "1:3"},
+		{7, "2:1"},
+		{100, "2:1"},
+	}
+
+	for _, test := range tests {
+		if actual := positions.Lookup(test.offset).String(); actual != test.expected {
+			t.Errorf("offset %d expected to map to %s. Got %s", test.offset, test.expected, actual)
+		}
+	}
+}
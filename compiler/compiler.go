@@ -0,0 +1,680 @@
+package compiler
+
+import (
+	"fmt"
+	"node.go/ast"
+	"node.go/code"
+	"node.go/object"
+	"node.go/token"
+)
+
+// Bytecode is the outcome of the compilation: the instructions of the main
+// program along with the constant pool they refer to
+type Bytecode struct {
+	Instructions code.Instructions
+	Positions    code.Positions
+	Constants    []object.Object
+	GlobalNames  []string // the name of each global slot, for error messages
+	NumLocals    int      // slots of the main program for the bindings of its blocks
+}
+
+// CompilationScope holds the instructions of the function being compiled
+type CompilationScope struct {
+	instructions code.Instructions
+	positions    code.Positions
+	lastPosition int // offset of the last emitted instruction
+
+	loops []*loop // innermost last
+}
+
+// loop tracks the jumps of break and continue statements
+type loop struct {
+	start  int   // offset continue jumps to
+	breaks []int // jumps to patch with the end of the loop
+}
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+	builtins    object.Builtins
+
+	scopes     []CompilationScope
+	scopeIndex int
+}
+
+func New() *Compiler {
+	return NewWithState(NewSymbolTable(), []object.Object{})
+}
+
+// NewWithState creates a compiler that keeps on defining globals and constants
+// where a previous compilation left off, as the repl does between lines
+func NewWithState(symbolTable *SymbolTable, constants []object.Object) *Compiler {
+	return &Compiler{
+		constants:   constants,
+		symbolTable: symbolTable,
+		scopes:      []CompilationScope{{}},
+	}
+}
+
+// SetBuiltins gives the program builtins of its own, resolved after the
+// globals and before the shared builtins
+func (c *Compiler) SetBuiltins(builtins object.Builtins) {
+	c.builtins = builtins
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	scope := c.scopes[c.scopeIndex]
+	return &Bytecode{
+		Instructions: scope.instructions,
+		Positions:    scope.positions,
+		Constants:    c.constants,
+		GlobalNames:  c.symbolTable.GlobalNames(),
+		NumLocals:    c.symbolTable.NumLocals(),
+	}
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	switch node := node.(type) {
+	case *ast.Program:
+		// The slots of the blocks of previous programs are free again
+		c.symbolTable.Globals().numLocals = 0
+		for _, name := range ast.VarNames(node.Statements) {
+			symbol, ok := c.symbolTable.Declared(name)
+			if !ok {
+				symbol = c.symbolTable.DefineKind(name, object.VAR_BINDING)
+			}
+			c.emit(code.OpDeclareGlobal, symbol.Index)
+		}
+		// The program leaves its value to the virtual machine as a function would
+		if err := c.compileBlock(node.Statements); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+	case *ast.ExpressionStatement:
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+	case *ast.LetStatement:
+		return c.compileLetStatement(node)
+	case *ast.AssignExpression:
+		return c.compileAssignExpression(node)
+	case *ast.LogicalExpression:
+		return c.compileLogicalExpression(node)
+	case *ast.WhileStatement:
+		return c.compileWhileStatement(node)
+	case *ast.ForInStatement:
+		return c.compileForInStatement(node)
+	case *ast.BreakStatement:
+		loop := c.currentLoop()
+		loop.breaks = append(loop.breaks, c.emit(code.OpJump, 0))
+	case *ast.ContinueStatement:
+		c.emit(code.OpJump, c.currentLoop().start)
+	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			c.emit(code.OpNull)
+		} else if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+	case *ast.PrefixExpression:
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		operator, ok := code.OperatorIndex(node.Operator)
+		if !ok {
+			return fmt.Errorf("%s: unknown operator %s", node.Pos(), node.Operator)
+		}
+		c.emit(code.OpPrefix, operator)
+		c.mark(node)
+	case *ast.InfixExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		operator, ok := code.OperatorIndex(node.Operator)
+		if !ok {
+			return fmt.Errorf("%s: unknown operator %s", node.Pos(), node.Operator)
+		}
+		c.emit(code.OpInfix, operator)
+		c.mark(node)
+	case *ast.IfExpression:
+		return c.compileIfExpression(node)
+	case *ast.Identifier:
+		c.compileIdentifier(node)
+	case *ast.IntegerLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.NewInteger(node.Value)))
+	case *ast.FloatLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.NewFloat(node.Value)))
+	case *ast.StringLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.NewString(node.Value)))
+	case *ast.BooleanLiteral:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.ArrayLiteral:
+		for _, item := range node.Items {
+			if err := c.Compile(item); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpArray, len(node.Items))
+	case *ast.HashLiteral:
+		return c.compileHashLiteral(node)
+	case *ast.TemplateLiteral:
+		return c.compileTemplateLiteral(node)
+	case *ast.IndexExpression:
+		if err := c.Compile(node.Container); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Index); err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+		c.mark(node)
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(node, "")
+	case *ast.CallExpression:
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+		for _, argument := range node.Arguments {
+			if err := c.Compile(argument); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpCall, len(node.Arguments))
+		c.mark(node)
+	default:
+		return fmt.Errorf("unable to compile %T", node)
+	}
+
+	return nil
+}
+
+// compileBlock compiles the statements so that they leave a single value on
+// the stack: the one of the last expression statement, null otherwise
+func (c *Compiler) compileBlock(statements []ast.Statement) error {
+	if len(statements) < 1 {
+		c.emit(code.OpNull)
+		return nil
+	}
+
+	last := len(statements) - 1
+	for _, statement := range statements[:last] {
+		if err := c.Compile(statement); err != nil {
+			return err
+		}
+	}
+
+	if expression, ok := statements[last].(*ast.ExpressionStatement); ok {
+		return c.Compile(expression.Expression)
+	}
+	if err := c.Compile(statements[last]); err != nil {
+		return err
+	}
+	c.emit(code.OpNull)
+	return nil
+}
+
+// compileScopedBlock compiles a block whose declarations are only visible
+// within it
+func (c *Compiler) compileScopedBlock(statements []ast.Statement) error {
+	c.symbolTable = NewBlockSymbolTable(c.symbolTable)
+	err := c.compileBlock(statements)
+	c.symbolTable = c.symbolTable.Outer
+	return err
+}
+
+// compileStatements compiles statements which leave nothing on the stack
+func (c *Compiler) compileStatements(statements []ast.Statement) error {
+	for _, statement := range statements {
+		if err := c.Compile(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) compileWhileStatement(node *ast.WhileStatement) error {
+	start := len(c.currentInstructions())
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+	jumpNotTruthy := c.emit(code.OpJumpNotTruthy, 0)
+
+	breaks, err := c.compileLoopBody(start, node.Body.Statements)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpJump, start)
+
+	end := len(c.currentInstructions())
+	c.changeOperand(jumpNotTruthy, end)
+	c.changeOperands(breaks, end)
+	return nil
+}
+
+// compileForInStatement keeps the iterator on the stack while the loop runs.
+// The loop variables are locals, of the main program at the top level, which
+// OpSetLocal binds anew at each iteration: as with the evaluator, closures
+// capture the variables of the iteration that created them.
+func (c *Compiler) compileForInStatement(node *ast.ForInStatement) error {
+	if err := c.Compile(node.Iterable); err != nil {
+		return err
+	}
+	variables := 1
+	if node.Key != nil {
+		variables = 2
+	}
+	c.emit(code.OpIterator, variables)
+	c.mark(node.Iterable)
+	next := c.emit(code.OpIterNext, 0)
+
+	c.symbolTable = NewBlockSymbolTable(c.symbolTable)
+	c.setSymbol(c.symbolTable.Define(node.Value.Value))
+	if node.Key != nil {
+		c.setSymbol(c.symbolTable.Define(node.Key.Value))
+	}
+	breaks, err := c.compileLoopBody(next, node.Body.Statements)
+	c.symbolTable = c.symbolTable.Outer
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpJump, next)
+
+	// Both breaking out and running out of items drop the iterator
+	end := len(c.currentInstructions())
+	c.changeOperand(next, end)
+	c.changeOperands(breaks, end)
+	c.emit(code.OpPop)
+	return nil
+}
+
+// compileLoopBody compiles the body of a loop whose continue statements jump
+// to start. It returns the jumps of the break statements, to be given the end
+// of the loop.
+func (c *Compiler) compileLoopBody(start int, body []ast.Statement) ([]int, error) {
+	scope := &c.scopes[c.scopeIndex]
+	scope.loops = append(scope.loops, &loop{start: start})
+
+	c.symbolTable = NewBlockSymbolTable(c.symbolTable)
+	err := c.compileStatements(body)
+	c.symbolTable = c.symbolTable.Outer
+
+	scope = &c.scopes[c.scopeIndex]
+	current := scope.loops[len(scope.loops)-1]
+	scope.loops = scope.loops[:len(scope.loops)-1]
+	return current.breaks, err
+}
+
+func (c *Compiler) currentLoop() *loop {
+	loops := c.scopes[c.scopeIndex].loops
+	return loops[len(loops)-1]
+}
+
+// compileLetStatement binds the value to the name within the block, or within
+// the function for var. Functions are named after their binding, which they
+// can refer to so as to call themselves: locals are bound beforehand so that
+// the closure captures them.
+func (c *Compiler) compileLetStatement(node *ast.LetStatement) error {
+	name := node.Name.Value
+	kind := bindingKind(node.Token.Type)
+	table := c.symbolTable
+	if kind == object.VAR_BINDING {
+		table = table.Function()
+	}
+	if existing, ok := table.Declared(name); ok {
+		if existing.Kind == object.CONST_BINDING {
+			c.emitError(node, "type error: cannot redeclare constant %s", name)
+			return nil
+		}
+		if kind == object.CONST_BINDING {
+			c.emitError(node, "type error: cannot redeclare %s as a constant", name)
+			return nil
+		}
+		// Redeclaring a var leaves its value alone
+		if node.Value == nil && kind == object.VAR_BINDING {
+			table.DefineKind(name, kind)
+			return nil
+		}
+	}
+
+	function, ok := node.Value.(*ast.FunctionLiteral)
+	if !ok {
+		if node.Value == nil {
+			c.emit(code.OpNull)
+		} else if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.setSymbol(table.DefineKind(name, kind))
+		return nil
+	}
+
+	symbol := table.DefineKind(name, kind)
+	if symbol.Scope == GLOBAL {
+		if err := c.compileFunctionLiteral(function, name); err != nil {
+			return err
+		}
+		c.setSymbol(symbol)
+		return nil
+	}
+
+	// A fresh binding, rather than one captured by a previous closure
+	c.emit(code.OpNull)
+	c.setSymbol(symbol)
+	if err := c.compileFunctionLiteral(function, name); err != nil {
+		return err
+	}
+	c.emit(code.OpAssignLocal, symbol.Index)
+	c.emit(code.OpPop)
+	return nil
+}
+
+func bindingKind(tokenType token.TokenType) object.BindingKind {
+	switch tokenType {
+	case token.CONST:
+		return object.CONST_BINDING
+	case token.VAR:
+		return object.VAR_BINDING
+	}
+	return object.LET_BINDING
+}
+
+// compileTemplateLiteral pushes the non empty strings of the template and its
+// expressions in order, for OpTemplate to join them
+func (c *Compiler) compileTemplateLiteral(node *ast.TemplateLiteral) error {
+	count := 0
+	for index, str := range node.Strings {
+		if str != "" {
+			c.emit(code.OpConstant, c.addConstant(object.NewString(str)))
+			count++
+		}
+		if index < len(node.Expressions) {
+			if err := c.Compile(node.Expressions[index]); err != nil {
+				return err
+			}
+			count++
+		}
+	}
+	c.emit(code.OpTemplate, count)
+	return nil
+}
+
+var logicalJumps = map[string]code.Opcode{
+	token.AND:     code.OpJumpFalsy,
+	token.OR:      code.OpJumpTruthy,
+	token.NULLISH: code.OpJumpNotNull,
+}
+
+// compileLogicalExpression skips the right operand when the left one decides
+// the result, leaving it on the stack
+func (c *Compiler) compileLogicalExpression(node *ast.LogicalExpression) error {
+	if err := c.Compile(node.Left); err != nil {
+		return err
+	}
+	jump := c.emit(logicalJumps[node.Operator], 0)
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+	c.changeOperand(jump, len(c.currentInstructions()))
+	return nil
+}
+
+// compileAssignExpression leaves the assigned value on the stack. Compound
+// assignments apply their operator to the current value of the target first.
+func (c *Compiler) compileAssignExpression(node *ast.AssignExpression) error {
+	operator, compound := token.CompoundOperator(node.Operator)
+	operatorIndex := 0
+	if compound {
+		index, ok := code.OperatorIndex(operator)
+		if !ok {
+			return fmt.Errorf("%s: unknown operator %s", node.Pos(), node.Operator)
+		}
+		operatorIndex = index
+	}
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		symbol, ok := c.symbolTable.Resolve(target.Value)
+		if !ok {
+			// Reported as undefined by the virtual machine
+			symbol = c.symbolTable.Globals().Define(target.Value)
+		}
+		if compound {
+			c.loadSymbol(symbol)
+			c.mark(target)
+		}
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		if compound {
+			c.emit(code.OpInfix, operatorIndex)
+			c.mark(node)
+		}
+		if symbol.Kind == object.CONST_BINDING {
+			c.emitError(node, "type error: cannot assign to constant %s", target.Value)
+			return nil
+		}
+		c.assignSymbol(symbol)
+		c.mark(node)
+	case *ast.IndexExpression:
+		if err := c.Compile(target.Container); err != nil {
+			return err
+		}
+		if err := c.Compile(target.Index); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		if compound {
+			c.emit(code.OpSetIndex, operatorIndex+1)
+		} else {
+			c.emit(code.OpSetIndex, 0)
+		}
+		c.mark(node)
+	default:
+		return fmt.Errorf("%s: cannot assign to %s", node.Pos(), node.Target)
+	}
+	return nil
+}
+
+func (c *Compiler) compileIfExpression(node *ast.IfExpression) error {
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+
+	jumpNotTruthy := c.emit(code.OpJumpNotTruthy, 0)
+	if err := c.compileScopedBlock(node.Consequence.Statements); err != nil {
+		return err
+	}
+	jump := c.emit(code.OpJump, 0)
+
+	c.changeOperand(jumpNotTruthy, len(c.currentInstructions()))
+	if node.Alternative == nil {
+		c.emit(code.OpNull)
+	} else if err := c.compileScopedBlock(node.Alternative.Statements); err != nil {
+		return err
+	}
+
+	c.changeOperand(jump, len(c.currentInstructions()))
+	return nil
+}
+
+func (c *Compiler) compileIdentifier(node *ast.Identifier) {
+	if symbol, ok := c.symbolTable.Resolve(node.Value); ok {
+		c.loadSymbol(symbol)
+		c.mark(node)
+		return
+	}
+	if builtin, ok := c.builtins.LookUp(node.Value); ok {
+		c.emit(code.OpConstant, c.addConstant(builtin))
+		return
+	}
+	// The global may still be defined before the code runs, as when a function
+	// refers to another one declared after it. Otherwise it is reported as
+	// undefined by the virtual machine.
+	c.loadSymbol(c.symbolTable.Globals().Define(node.Value))
+	c.mark(node)
+}
+
+func (c *Compiler) setSymbol(symbol Symbol) {
+	if symbol.Scope == GLOBAL {
+		c.emit(code.OpSetGlobal, symbol.Index)
+	} else {
+		c.emit(code.OpSetLocal, symbol.Index)
+	}
+}
+
+func (c *Compiler) loadSymbol(symbol Symbol) {
+	switch symbol.Scope {
+	case GLOBAL:
+		c.emit(code.OpGetGlobal, symbol.Index)
+	case LOCAL:
+		c.emit(code.OpGetLocal, symbol.Index)
+	case FREE:
+		c.emit(code.OpGetFree, symbol.Index)
+	}
+}
+
+func (c *Compiler) assignSymbol(symbol Symbol) {
+	switch symbol.Scope {
+	case GLOBAL:
+		c.emit(code.OpAssignGlobal, symbol.Index)
+	case LOCAL:
+		c.emit(code.OpAssignLocal, symbol.Index)
+	case FREE:
+		c.emit(code.OpAssignFree, symbol.Index)
+	}
+}
+
+// captureSymbol pushes the cell of a variable captured by a closure
+func (c *Compiler) captureSymbol(symbol Symbol) {
+	if symbol.Scope == FREE {
+		c.emit(code.OpCaptureFree, symbol.Index)
+	} else {
+		c.emit(code.OpCaptureLocal, symbol.Index)
+	}
+}
+
+// compileHashLiteral emits the pairs in source order, the order the hash keeps
+func (c *Compiler) compileHashLiteral(node *ast.HashLiteral) error {
+	for _, pair := range node.Pairs {
+		if err := c.Compile(pair.Key); err != nil {
+			return err
+		}
+		if err := c.Compile(pair.Value); err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpHash, len(node.Pairs)*2)
+	c.mark(node)
+	return nil
+}
+
+func (c *Compiler) compileFunctionLiteral(node *ast.FunctionLiteral, name string) error {
+	c.enterScope()
+
+	for _, parameter := range node.Parameters {
+		c.symbolTable.Define(parameter.Value)
+	}
+	// Hoisted vars are null until assigned, as their slots start out empty
+	for _, name := range ast.VarNames(node.Body.Statements) {
+		if _, ok := c.symbolTable.Declared(name); !ok {
+			c.symbolTable.DefineKind(name, object.VAR_BINDING)
+		}
+	}
+
+	if err := c.compileBlock(node.Body.Statements); err != nil {
+		return err
+	}
+	c.emit(code.OpReturnValue)
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.numDefinitions
+	scope := c.leaveScope()
+
+	for _, symbol := range freeSymbols {
+		c.captureSymbol(symbol)
+	}
+
+	function := &object.CompiledFunction{
+		Name:          name,
+		Instructions:  scope.instructions,
+		Positions:     scope.positions,
+		NumLocals:     numLocals,
+		NumParameters: len(node.Parameters),
+		Literal:       node,
+	}
+	c.emit(code.OpClosure, c.addConstant(function), len(freeSymbols))
+	return nil
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// emitError raises a runtime error, for the mistakes the compiler finds out
+// about but the evaluator only reports once it runs into them
+func (c *Compiler) emitError(node ast.Node, format string, params ...interface{}) {
+	message := object.NewString(fmt.Sprintf(format, params...))
+	c.emit(code.OpError, c.addConstant(message))
+	c.mark(node)
+}
+
+// emit appends the instruction to the current scope and returns its offset
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	scope := &c.scopes[c.scopeIndex]
+	position := len(scope.instructions)
+	scope.instructions = append(scope.instructions, code.Make(op, operands...)...)
+	scope.lastPosition = position
+	return position
+}
+
+// mark maps the last emitted instruction to the node, so that the errors it
+// raises can be located
+func (c *Compiler) mark(node ast.Node) {
+	scope := &c.scopes[c.scopeIndex]
+	scope.positions = append(scope.positions, code.SourcePosition{
+		Offset: scope.lastPosition,
+		Pos:    node.Pos(),
+	})
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) changeOperand(position int, operands ...int) {
+	instructions := c.currentInstructions()
+	op := code.Opcode(instructions[position])
+	copy(instructions[position:], code.Make(op, operands...))
+}
+
+// changeOperands gives the same operand to each of the jumps
+func (c *Compiler) changeOperands(jumps []int, operand int) {
+	for _, jump := range jumps {
+		c.changeOperand(jump, operand)
+	}
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, CompilationScope{})
+	c.scopeIndex++
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() CompilationScope {
+	scope := c.scopes[c.scopeIndex]
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+	c.symbolTable = c.symbolTable.Outer
+	return scope
+}
@@ -0,0 +1,259 @@
+package compiler
+
+import (
+	"node.go/ast"
+	"node.go/code"
+	"node.go/lexer"
+	"node.go/object"
+	"node.go/parser"
+	"testing"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	par := parser.New(lexer.New(input))
+	program := par.ParseProgram()
+	if len(par.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", par.Errors())
+	}
+	return program
+}
+
+func concatInstructions(instructions ...[]byte) code.Instructions {
+	var out code.Instructions
+	for _, instruction := range instructions {
+		out = append(out, instruction...)
+	}
+	return out
+}
+
+func testInstructions(t *testing.T, expected code.Instructions, actual code.Instructions) {
+	if expected.String() != actual.String() {
+		t.Fatalf("wrong instructions.\nExpected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestCompileExpressions(t *testing.T) {
+	plus, _ := code.OperatorIndex("+")
+	bang, _ := code.OperatorIndex("!")
+
+	tests := []struct {
+		input    string
+		expected code.Instructions
+	}{
+		{"1 + 2", concatInstructions(
+			code.Make(code.OpConstant, 0),
+			code.Make(code.OpConstant, 1),
+			code.Make(code.OpInfix, plus),
+			code.Make(code.OpReturnValue),
+		)},
+		{"!true; false", concatInstructions(
+			code.Make(code.OpTrue),
+			code.Make(code.OpPrefix, bang),
+			code.Make(code.OpPop),
+			code.Make(code.OpFalse),
+			code.Make(code.OpReturnValue),
+		)},
+		{"let x = 1;", concatInstructions(
+			code.Make(code.OpConstant, 0),
+			code.Make(code.OpSetGlobal, 0),
+			code.Make(code.OpNull),
+			code.Make(code.OpReturnValue),
+		)},
+		{"if (true) { 10 }", concatInstructions(
+			code.Make(code.OpTrue),
+			code.Make(code.OpJumpNotTruthy, 10),
+			code.Make(code.OpConstant, 0),
+			code.Make(code.OpJump, 11),
+			code.Make(code.OpNull),
+			code.Make(code.OpReturnValue),
+		)},
+		{"[1, 2][0]", concatInstructions(
+			code.Make(code.OpConstant, 0),
+			code.Make(code.OpConstant, 1),
+			code.Make(code.OpArray, 2),
+			code.Make(code.OpConstant, 2),
+			code.Make(code.OpIndex),
+			code.Make(code.OpReturnValue),
+		)},
+		{"true && 1", concatInstructions(
+			code.Make(code.OpTrue),
+			code.Make(code.OpJumpFalsy, 7),
+			code.Make(code.OpConstant, 0),
+			code.Make(code.OpReturnValue),
+		)},
+		{"len([])", concatInstructions(
+			code.Make(code.OpConstant, 0),
+			code.Make(code.OpArray, 0),
+			code.Make(code.OpCall, 1),
+			code.Make(code.OpReturnValue),
+		)},
+	}
+
+	for _, test := range tests {
+		compiler := New()
+		if err := compiler.Compile(parse(t, test.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+		testInstructions(t, test.expected, compiler.Bytecode().Instructions)
+	}
+}
+
+func TestCompileClosures(t *testing.T) {
+	input := `
+let adder = fn(a) {
+	fn(b) { a + b }
+};`
+
+	compiler := New()
+	if err := compiler.Compile(parse(t, input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	bytecode := compiler.Bytecode()
+
+	plus, _ := code.OperatorIndex("+")
+	inner := bytecode.Constants[0].(*object.CompiledFunction)
+	testInstructions(t, concatInstructions(
+		code.Make(code.OpGetFree, 0),
+		code.Make(code.OpGetLocal, 0),
+		code.Make(code.OpInfix, plus),
+		code.Make(code.OpReturnValue),
+	), inner.Instructions)
+
+	outer := bytecode.Constants[1].(*object.CompiledFunction)
+	if outer.Name != "adder" {
+		t.Errorf("wrong function name. Expected adder. Got %s", outer.Name)
+	}
+	testInstructions(t, concatInstructions(
+		code.Make(code.OpCaptureLocal, 0),
+		code.Make(code.OpClosure, 0, 1),
+		code.Make(code.OpReturnValue),
+	), outer.Instructions)
+}
+
+func TestCompileRecursiveFunction(t *testing.T) {
+	compiler := New()
+	if err := compiler.Compile(parse(t, "let loop = fn(x) { loop(x) };")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	function := compiler.Bytecode().Constants[0].(*object.CompiledFunction)
+	testInstructions(t, concatInstructions(
+		code.Make(code.OpGetGlobal, 0),
+		code.Make(code.OpGetLocal, 0),
+		code.Make(code.OpCall, 1),
+		code.Make(code.OpReturnValue),
+	), function.Instructions)
+}
+
+func TestSourcePositions(t *testing.T) {
+	compiler := New()
+	if err := compiler.Compile(parse(t, "let x = 1;\nx + y")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	bytecode := compiler.Bytecode()
+
+	// let x = 1; leaves 6 bytes: OpConstant 0, OpSetGlobal 0
+	tests := []struct {
+		offset int
+		line   int
+		column int
+	}{
+		{6, 2, 1},  // x
+		{9, 2, 5},  // y
+		{12, 2, 1}, // +
+	}
+
+	for _, test := range tests {
+		pos := bytecode.Positions.Lookup(test.offset)
+		if pos.Line != test.line || pos.Column != test.column {
+			t.Errorf("wrong position at offset %d. Expected %d:%d. Got %s",
+				test.offset, test.line, test.column, pos)
+		}
+	}
+	if names := bytecode.GlobalNames; len(names) != 2 || names[1] != "y" {
+		t.Errorf("wrong global names. Got %v", names)
+	}
+}
+
+func TestSymbolTable(t *testing.T) {
+	global := NewSymbolTable()
+	a := global.Define("a")
+	local := NewEnclosedSymbolTable(global)
+	b := local.Define("b")
+	nested := NewEnclosedSymbolTable(local)
+
+	tests := []struct {
+		table    *SymbolTable
+		name     string
+		expected Symbol
+	}{
+		{global, "a", a},
+		{local, "b", b},
+		{nested, "a", Symbol{Name: "a", Scope: GLOBAL, Index: 0}},
+		{nested, "b", Symbol{Name: "b", Scope: FREE, Index: 0}},
+	}
+
+	for _, test := range tests {
+		symbol, ok := test.table.Resolve(test.name)
+		if !ok {
+			t.Fatalf("%s not resolved", test.name)
+		}
+		if symbol != test.expected {
+			t.Errorf("wrong symbol for %s. Expected %+v. Got %+v", test.name, test.expected, symbol)
+		}
+	}
+
+	if redefined := global.Define("a"); redefined != a {
+		t.Errorf("redefinition should reuse the slot. Expected %+v. Got %+v", a, redefined)
+	}
+	if _, ok := nested.Resolve("c"); ok {
+		t.Errorf("undefined name resolved")
+	}
+}
+
+func TestSymbolKinds(t *testing.T) {
+	global := NewSymbolTable()
+	global.DefineKind("limit", object.CONST_BINDING)
+	function := NewEnclosedSymbolTable(global)
+	block := NewBlockSymbolTable(function)
+	inner := NewEnclosedSymbolTable(block)
+	block.DefineKind("total", object.CONST_BINDING)
+	v := block.Function().DefineKind("count", object.VAR_BINDING)
+
+	if v.Scope != LOCAL || v.Index != 1 {
+		t.Errorf("var expected to take the next slot of the function. Got %+v", v)
+	}
+	if _, ok := function.Declared("total"); ok {
+		t.Errorf("constant of the block declared within the function")
+	}
+	if _, ok := block.Declared("count"); ok {
+		t.Errorf("var of the function declared within the block")
+	}
+	for _, name := range []string{"limit", "total"} {
+		symbol, ok := inner.Resolve(name)
+		if !ok || symbol.Kind != object.CONST_BINDING {
+			t.Errorf("%s expected to resolve to a constant. Got %+v", name, symbol)
+		}
+	}
+}
+
+func TestTopLevelBlockSymbols(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+	block := NewBlockSymbolTable(global)
+	b := block.Define("b")
+	v := block.Function().DefineKind("v", object.VAR_BINDING)
+	function := NewEnclosedSymbolTable(block)
+
+	if b.Scope != LOCAL || b.Index != 0 {
+		t.Errorf("block binding expected to take the first slot of the main program. Got %+v", b)
+	}
+	if v.Scope != GLOBAL || v.Index != 1 {
+		t.Errorf("var expected to take the next global. Got %+v", v)
+	}
+	if free, ok := function.Resolve("b"); !ok || free.Scope != FREE {
+		t.Errorf("block binding expected to be captured by functions. Got %+v", free)
+	}
+	if global.NumLocals() != 1 {
+		t.Errorf("main program expected to need 1 slot. Got %d", global.NumLocals())
+	}
+}
@@ -0,0 +1,155 @@
+package compiler
+
+import "node.go/object"
+
+type SymbolScope string
+
+const (
+	GLOBAL SymbolScope = "GLOBAL"
+	LOCAL  SymbolScope = "LOCAL"
+	FREE   SymbolScope = "FREE"
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+	Kind  object.BindingKind
+}
+
+// SymbolTable resolves the identifiers of a function, or of the whole program
+// for the outermost table, to their slots
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	FreeSymbols []Symbol
+
+	store          map[string]Symbol
+	numDefinitions int
+	names          []string // names of the globals by index
+	numLocals      int      // slots of the main program, for the outermost table
+
+	// Set for the tables of blocks, whose symbols live in the slots of the
+	// enclosing function or program
+	block bool
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol)}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	table := NewSymbolTable()
+	table.Outer = outer
+	return table
+}
+
+// NewBlockSymbolTable creates the table of a block, whose names are only
+// visible within the block but take their slots from the enclosing function
+func NewBlockSymbolTable(outer *SymbolTable) *SymbolTable {
+	table := NewEnclosedSymbolTable(outer)
+	table.block = true
+	return table
+}
+
+// Define binds the name within the table as let does
+func (s *SymbolTable) Define(name string) Symbol {
+	return s.DefineKind(name, object.LET_BINDING)
+}
+
+// DefineKind binds the name within the table. Redefining a name reuses its
+// slot.
+func (s *SymbolTable) DefineKind(name string, kind object.BindingKind) Symbol {
+	if symbol, ok := s.Declared(name); ok {
+		symbol.Kind = kind
+		s.store[name] = symbol
+		return symbol
+	}
+
+	owner := s.Function()
+
+	symbol := Symbol{Name: name, Scope: LOCAL, Kind: kind}
+	switch {
+	case owner.Outer != nil:
+		symbol.Index = owner.numDefinitions
+		owner.numDefinitions++
+	case s.block:
+		// The blocks of the top level bind their names in slots of the main
+		// program rather than globals, so that each iteration of a loop gets
+		// bindings of its own for closures to capture
+		symbol.Index = owner.numLocals
+		owner.numLocals++
+	default:
+		symbol.Scope = GLOBAL
+		symbol.Index = owner.numDefinitions
+		owner.names = append(owner.names, name)
+		owner.numDefinitions++
+	}
+
+	s.store[name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1, Scope: FREE, Kind: original.Kind}
+	s.store[original.Name] = symbol
+	return symbol
+}
+
+// Declared looks the name up within the table only, leaving aside free
+// variables which are bound by enclosing functions
+func (s *SymbolTable) Declared(name string) (Symbol, bool) {
+	symbol, ok := s.store[name]
+	if !ok || symbol.Scope == FREE {
+		return Symbol{}, false
+	}
+	return symbol, true
+}
+
+// Function returns the table of the enclosing function, or the outermost one
+// at the top level, which var declarations are bound in
+func (s *SymbolTable) Function() *SymbolTable {
+	table := s
+	for table.block {
+		table = table.Outer
+	}
+	return table
+}
+
+// Resolve looks the name up through the enclosing tables. Locals of enclosing
+// functions become free variables of this one.
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	symbol, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return symbol, ok
+	}
+
+	symbol, ok = s.Outer.Resolve(name)
+	if !ok || symbol.Scope == GLOBAL || s.block {
+		return symbol, ok
+	}
+
+	return s.defineFree(symbol), true
+}
+
+// Globals returns the outermost table, the one holding the global bindings
+func (s *SymbolTable) Globals() *SymbolTable {
+	table := s
+	for table.Outer != nil {
+		table = table.Outer
+	}
+	return table
+}
+
+// NumLocals returns the number of slots the main program needs for the
+// bindings of its blocks
+func (s *SymbolTable) NumLocals() int {
+	return s.Globals().numLocals
+}
+
+// GlobalNames returns the names of the globals indexed by slot
+func (s *SymbolTable) GlobalNames() []string {
+	return s.Globals().names
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"fmt"
+	"node.go/ast"
+	"node.go/compiler"
+	"node.go/evaluator"
+	"node.go/object"
+	"node.go/vm"
+)
+
+// Engine names
+const (
+	EVALUATOR = "eval"
+	VM        = "vm"
+)
+
+// Names lists the available engines, the default one first
+var Names = []string{EVALUATOR, VM}
+
+// Engine runs programs one after the other, each one seeing the globals
+// defined by the previous ones
+type Engine interface {
+	// Define binds a global before running any program
+	Define(name string, value object.Object)
+	// SetBuiltins gives the programs builtins of their own, looked up after
+	// the globals and before the shared builtins
+	SetBuiltins(builtins object.Builtins)
+	Run(program *ast.Program) object.Object
+	// Global looks up a global left by the programs run so far
+	Global(name string) (object.Object, bool)
+	// Call calls a function, such as one defined by a program, from Go
+	Call(function object.Object, arguments []object.Object) object.Object
+}
+
+func New(name string) (Engine, error) {
+	switch name {
+	case EVALUATOR:
+		return NewEvaluator(), nil
+	case VM:
+		return NewVM(), nil
+	}
+	return nil, fmt.Errorf("unknown engine %q", name)
+}
+
+// Evaluator walks the syntax tree
+type Evaluator struct {
+	evaluator   *evaluator.Evaluator
+	environment *object.Environment
+}
+
+func NewEvaluator() *Evaluator {
+	return NewEvaluatorWithOptions(evaluator.Options{})
+}
+
+// NewEvaluatorWithOptions creates an evaluator engine running each program
+// within the given limits
+func NewEvaluatorWithOptions(options evaluator.Options) *Evaluator {
+	return &Evaluator{
+		evaluator:   evaluator.New(options),
+		environment: object.NewEnvironment(),
+	}
+}
+
+func (e *Evaluator) Define(name string, value object.Object) {
+	e.environment.Set(name, value)
+}
+
+func (e *Evaluator) SetBuiltins(builtins object.Builtins) {
+	e.evaluator.SetBuiltins(builtins)
+}
+
+func (e *Evaluator) Run(program *ast.Program) object.Object {
+	return e.evaluator.Eval(program, e.environment)
+}
+
+func (e *Evaluator) Global(name string) (object.Object, bool) {
+	return e.environment.Get(name)
+}
+
+func (e *Evaluator) Call(function object.Object, arguments []object.Object) object.Object {
+	return e.evaluator.Apply(function, arguments)
+}
+
+// VirtualMachine compiles the program to bytecode and runs it on the vm
+type VirtualMachine struct {
+	symbolTable *compiler.SymbolTable
+	builtins    object.Builtins
+	bytecode    *compiler.Bytecode // of the last program run
+	globals     []object.Object
+}
+
+func NewVM() *VirtualMachine {
+	return &VirtualMachine{
+		symbolTable: compiler.NewSymbolTable(),
+		bytecode:    &compiler.Bytecode{Constants: []object.Object{}},
+		globals:     make([]object.Object, vm.GLOBALS_SIZE),
+	}
+}
+
+func (v *VirtualMachine) Define(name string, value object.Object) {
+	symbol := v.symbolTable.Define(name)
+	v.globals[symbol.Index] = value
+}
+
+func (v *VirtualMachine) SetBuiltins(builtins object.Builtins) {
+	v.builtins = builtins
+}
+
+func (v *VirtualMachine) Run(program *ast.Program) object.Object {
+	comp := compiler.NewWithState(v.symbolTable, v.bytecode.Constants)
+	comp.SetBuiltins(v.builtins)
+	if err := comp.Compile(program); err != nil {
+		return object.NewError(fmt.Sprintf("compile error: %s", err))
+	}
+
+	v.bytecode = comp.Bytecode()
+
+	machine := vm.NewWithGlobalsStore(v.bytecode, v.globals)
+	machine.SetBuiltins(v.builtins)
+	return machine.Run()
+}
+
+func (v *VirtualMachine) Global(name string) (object.Object, bool) {
+	symbol, ok := v.symbolTable.Resolve(name)
+	if !ok || v.globals[symbol.Index] == nil {
+		return nil, false
+	}
+	return v.globals[symbol.Index], true
+}
+
+func (v *VirtualMachine) Call(function object.Object, arguments []object.Object) object.Object {
+	return vm.Call(v.bytecode, v.globals, v.builtins, function, arguments)
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"node.go/object"
+	"os"
+	"testing"
+)
+
+var benchmarks = []struct {
+	name string
+	code string
+}{
+	{"fibonacci", `
+let fib = fn(n) { if (n < 2) { return n } fib(n - 1) + fib(n - 2) };
+fib(20)`},
+	{"map", `
+let double = fn(x) { x * 2 };
+let range = fn(n) { let iter = fn(i, acc) { if (i == n) { return acc } iter(i + 1, push(acc, i)) }; iter(0, []) };
+map(range(500), double)`},
+	{"reduce", `
+let sum = fn(x, acc) { x + acc };
+let range = fn(n) { let iter = fn(i, acc) { if (i == n) { return acc } iter(i + 1, push(acc, i)) }; iter(0, []) };
+reduce(range(500), sum, 0)`},
+	{"closures", `
+let counter = fn(n) { let add = fn(x) { fn(y) { x + y } }; add(n) };
+let iter = fn(i, acc) { if (i == 0) { return acc } iter(i - 1, counter(i)(acc)) };
+iter(2000, 0)`},
+	{"hashes", `
+let build = fn(i, acc) { if (i == 0) { return acc } build(i - 1, push(acc, {"key": i, "double": i * 2})) };
+let total = fn(items, acc) { if (len(items) < 1) { return acc } total(tail(items), acc + head(items)["double"]) };
+total(build(300, []), 0)`},
+}
+
+// prelude defines the helpers of the examples directory
+func prelude(b *testing.B) string {
+	var code string
+	for _, example := range []string{"../examples/map.ngo", "../examples/reduce.ngo"} {
+		source, err := os.ReadFile(example)
+		if err != nil {
+			b.Fatal(err)
+		}
+		code += string(source) + "\n"
+	}
+	return code
+}
+
+// BenchmarkEngines runs every benchmark program on every engine, so that they
+// can be compared with go test -bench Engines
+func BenchmarkEngines(b *testing.B) {
+	helpers := prelude(b)
+
+	for _, benchmark := range benchmarks {
+		for _, name := range Names {
+			b.Run(benchmark.name+"/"+name, func(b *testing.B) {
+				program := parse(b, helpers+benchmark.code)
+				for i := 0; i < b.N; i++ {
+					engine, _ := New(name)
+					if err, ok := engine.Run(program).(*object.Error); ok {
+						b.Fatal(err.Traceback())
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package engine
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"node.go/ast"
+	"node.go/lexer"
+	"node.go/object"
+	"node.go/parser"
+	"strconv"
+	"testing"
+)
+
+// EVALUATOR_SUITE lists the evaluator tests whose programs every engine has
+// to run just like the evaluator does. Tests of features specific to the
+// evaluator, such as its limits, are left out.
+var EVALUATOR_SUITE = []string{
+	"../evaluator/evaluator_test.go",
+	"../evaluator/evaluator_loop_test.go",
+	"../evaluator/evaluator_assignment_test.go",
+	"../evaluator/evaluator_declaration_test.go",
+	"../evaluator/evaluator_float_test.go",
+	"../evaluator/evaluator_bigint_test.go",
+	"../evaluator/evaluator_power_test.go",
+	"../evaluator/evaluator_logical_test.go",
+	"../evaluator/evaluator_template_test.go",
+	"../evaluator/evaluator_unicode_test.go",
+	"../evaluator/evaluator_bitwise_test.go",
+	"../evaluator/evaluator_json_test.go",
+	"../evaluator/evaluator_hash_test.go",
+	"../evaluator/evaluator_equality_test.go",
+}
+
+func parse(t testing.TB, code string) *ast.Program {
+	par := parser.New(lexer.New(code))
+	program := par.ParseProgram()
+	if len(par.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", par.Errors())
+	}
+	return program
+}
+
+// suitePrograms extracts the programs from the string literals of the
+// evaluator tests. Literals which are not valid programs, such as expected
+// error messages, are left out.
+func suitePrograms(t *testing.T, filename string) []string {
+	file, err := goparser.ParseFile(gotoken.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		t.Fatalf("unable to read the evaluator tests: %s", err)
+	}
+
+	var programs []string
+	goast.Inspect(file, func(node goast.Node) bool {
+		literal, ok := node.(*goast.BasicLit)
+		if !ok || literal.Kind != gotoken.STRING {
+			return true
+		}
+		code, err := strconv.Unquote(literal.Value)
+		if err != nil {
+			return true
+		}
+		par := parser.New(lexer.New(code))
+		if program := par.ParseProgram(); len(par.Errors()) < 1 && len(program.Statements) > 0 {
+			programs = append(programs, code)
+		}
+		return true
+	})
+	return programs
+}
+
+func TestEnginesAgreeOnEvaluatorSuite(t *testing.T) {
+	var programs []string
+	for _, filename := range EVALUATOR_SUITE {
+		found := suitePrograms(t, filename)
+		if len(found) < 1 {
+			t.Fatalf("no programs found in %s", filename)
+		}
+		programs = append(programs, found...)
+	}
+
+	for _, name := range Names[1:] {
+		for _, code := range programs {
+			expected := NewEvaluator().Run(parse(t, code))
+			if expected == nil {
+				continue
+			}
+			engine, err := New(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := engine.Run(parse(t, code))
+			if !sameObject(expected, result) {
+				t.Errorf("%s engine disagrees on %q. Expected %s. Got %s",
+					name, code, describe(expected), describe(result))
+			}
+		}
+	}
+}
+
+func describe(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+	if err, ok := obj.(*object.Error); ok {
+		return err.Traceback()
+	}
+	return obj.Inspect()
+}
+
+// sameObject compares the results of two engines, which represent functions
+// differently and may print hashes in any order
+func sameObject(expected object.Object, result object.Object) bool {
+	if expected == nil || result == nil {
+		return expected == result
+	}
+	switch expected := expected.(type) {
+	case *object.Error:
+		result, ok := result.(*object.Error)
+		return ok && expected.Traceback() == result.Traceback()
+	case *object.Array:
+		result, ok := result.(*object.Array)
+		if !ok || len(expected.Items) != len(result.Items) {
+			return false
+		}
+		for index, item := range expected.Items {
+			if !sameObject(item, result.Items[index]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		result, ok := result.(*object.Hash)
+		if !ok || expected.Len() != result.Len() {
+			return false
+		}
+		// Both engines keep the pairs in the same order
+		for index, pair := range expected.Pairs() {
+			other := result.Pairs()[index]
+			if !sameObject(pair.Key, other.Key) || !sameObject(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return expected.Type() == result.Type() && expected.Inspect() == result.Inspect()
+}
+
+func TestEnginesKeepGlobalsBetweenRuns(t *testing.T) {
+	for _, name := range Names {
+		engine, err := New(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		engine.Define("base", object.NewInteger(10))
+		engine.Run(parse(t, "let add = fn(x) { base + x };"))
+		engine.Run(parse(t, "let twice = fn(x) { add(add(x)) };"))
+
+		result := engine.Run(parse(t, "twice(1)"))
+		integer, ok := result.(*object.Integer)
+		if !ok || integer.Value != 21 {
+			t.Errorf("%s engine: expected 21. Got %s", name, describe(result))
+		}
+	}
+}
+
+func TestUnknownEngine(t *testing.T) {
+	if _, err := New("jit"); err == nil {
+		t.Fatalf("expected an error for an unknown engine")
+	}
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"node.go/ast"
+	"node.go/object"
+	"node.go/token"
+)
+
+func (e *Evaluator) evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		return e.evalIdentifierAssignment(node, target, env)
+	case *ast.IndexExpression:
+		return e.evalIndexAssignment(node, target, env)
+	}
+	return newError("syntax error: cannot assign to %s", node.Target.String())
+}
+
+// evalIdentifierAssignment updates the nearest binding of the identifier, which
+// must have been defined beforehand
+func (e *Evaluator) evalIdentifierAssignment(
+	node *ast.AssignExpression, target *ast.Identifier, env *object.Environment) object.Object {
+	var current object.Object
+	if _, compound := token.CompoundOperator(node.Operator); compound {
+		current = e.evalIdentifierExpression(target, env)
+		if isError(current) {
+			return current
+		}
+	}
+	value := e.eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	value = applyAssignment(node.Operator, current, value)
+	if isError(value) {
+		return value
+	}
+	kind, ok := env.Lookup(target.Value)
+	if !ok {
+		return newError("reference error: %s is not defined", target.Value)
+	}
+	if kind == object.CONST_BINDING {
+		return newError("type error: cannot assign to constant %s", target.Value)
+	}
+	env.Assign(target.Value, value)
+	return value
+}
+
+// evalIndexAssignment stores the value into an array or a hash. The container
+// and the index are evaluated before the value.
+func (e *Evaluator) evalIndexAssignment(
+	node *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment) object.Object {
+	container := e.eval(target.Container, env)
+	if isError(container) {
+		return container
+	}
+	index := e.eval(target.Index, env)
+	if isError(index) {
+		return index
+	}
+	var current object.Object
+	if _, compound := token.CompoundOperator(node.Operator); compound {
+		current = EvalIndex(container, index)
+		if isError(current) {
+			return current
+		}
+	}
+	value := e.eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	value = applyAssignment(node.Operator, current, value)
+	if isError(value) {
+		return value
+	}
+	return SetIndex(container, index, value)
+}
+
+// applyAssignment computes the value stored by the assignment operator, given
+// the current value of its target for compound assignments
+func applyAssignment(assignment string, current object.Object, value object.Object) object.Object {
+	operator, compound := token.CompoundOperator(assignment)
+	if !compound {
+		return value
+	}
+	return evalInfixOperatorExpression(operator, current, value)
+}
+
+// SetIndex stores the value at the index of the container, returning the value
+// or an error. Arrays only accept indexes within their bounds.
+func SetIndex(container object.Object, index object.Object, value object.Object) object.Object {
+	switch obj := container.(type) {
+	case *object.Array:
+		if index.Type() != object.INT {
+			return newError("type error: %s cannot be used as index of %s", index.Type(), object.ARRAY)
+		}
+		position, ok := index.(*object.Integer)
+		if !ok || position.Value < 0 || position.Value >= int64(len(obj.Items)) {
+			return newError("index error: index %s out of range for %s of length %d",
+				index.Inspect(), object.ARRAY, len(obj.Items))
+		}
+		obj.Items[position.Value] = value
+		return value
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("value error: unhashable type as hash key: %s", index.Type())
+		}
+		obj.Set(key, value)
+		return value
+	}
+	return newError("type error: %s does not support index assignment", container.Type())
+}
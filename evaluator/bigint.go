@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"node.go/object"
+	"node.go/token"
+)
+
+// overflows tells whether applying the operator to the integers leaves the
+// range of an int64
+func overflows(operator string, left int64, right int64) bool {
+	switch operator {
+	case token.PLUS:
+		result := left + right
+		return (left^result)&(right^result) < 0
+	case token.MINUS:
+		result := left - right
+		return (left^right)&(left^result) < 0
+	case token.ASTERISK:
+		if left == 0 || right == 0 {
+			return false
+		}
+		result := left * right
+		// Dividing math.MinInt64 by -1 wraps too, so that product is checked apart
+		return result/right != left || (right == -1 && left == math.MinInt64)
+	case token.SLASH:
+		return left == math.MinInt64 && right == -1
+	}
+	return false
+}
+
+// evalInfixBigIntegerExpression applies the operator to integers whose values
+// or result do not fit an int64. Division truncates toward zero, as it does
+// for integers.
+func evalInfixBigIntegerExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftValue, _ := object.ToBigInt(left)
+	rightValue, _ := object.ToBigInt(right)
+	switch operator {
+	case token.PLUS:
+		return object.NewBigInteger(new(big.Int).Add(leftValue, rightValue))
+	case token.ASTERISK:
+		return object.NewBigInteger(new(big.Int).Mul(leftValue, rightValue))
+	case token.MINUS:
+		return object.NewBigInteger(new(big.Int).Sub(leftValue, rightValue))
+	case token.SLASH:
+		if rightValue.Sign() == 0 {
+			return object.NULL
+		}
+		return object.NewBigInteger(new(big.Int).Quo(leftValue, rightValue))
+	case token.PERCENT:
+		if rightValue.Sign() == 0 {
+			return object.NULL
+		}
+		return object.NewBigInteger(new(big.Int).Rem(leftValue, rightValue))
+	case token.EQ:
+		return booleanToObject(leftValue.Cmp(rightValue) == 0)
+	case token.NOT_EQ:
+		return booleanToObject(leftValue.Cmp(rightValue) != 0)
+	case token.LT:
+		return booleanToObject(leftValue.Cmp(rightValue) < 0)
+	case token.GT:
+		return booleanToObject(leftValue.Cmp(rightValue) > 0)
+	case token.LTE:
+		return booleanToObject(leftValue.Cmp(rightValue) <= 0)
+	case token.GTE:
+		return booleanToObject(leftValue.Cmp(rightValue) >= 0)
+	case token.BIT_AND:
+		return object.NewBigInteger(new(big.Int).And(leftValue, rightValue))
+	case token.BIT_OR:
+		return object.NewBigInteger(new(big.Int).Or(leftValue, rightValue))
+	}
+	return newError("unknown operator: %s%s", operator, object.INT)
+}
@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"math/big"
+	"node.go/object"
+	"node.go/token"
+)
+
+// evalBitwiseNotExpression complements the bits of an integer, so that ~x is
+// -x - 1
+func evalBitwiseNotExpression(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return object.NewInteger(^obj.Value)
+	case *object.BigInteger:
+		return object.NewBigInteger(new(big.Int).Not(obj.Value))
+	}
+	return newError("unknown operator: ~%s", obj.Type())
+}
+
+// evalShiftExpression shifts the bits of an integer by a count which cannot be
+// negative. Shifting right is arithmetic, it keeps the sign, while shifting
+// left promotes the integer to a big integer rather than losing bits.
+func evalShiftExpression(operator string, left object.Object, right object.Object) object.Object {
+	value, _ := object.ToBigInt(left)
+	count, _ := object.ToBigInt(right)
+
+	if count.Sign() < 0 {
+		return newError("value error: negative shift count %s", right.Inspect())
+	}
+	if operator == token.SHIFT_RIGHT {
+		if !count.IsInt64() || count.Int64() > int64(value.BitLen()) {
+			// Every bit is shifted out, leaving the sign
+			if value.Sign() < 0 {
+				return object.NewInteger(-1)
+			}
+			return object.NewInteger(0)
+		}
+		return object.NewBigInteger(new(big.Int).Rsh(value, uint(count.Int64())))
+	}
+
+	if value.Sign() == 0 {
+		return object.NewInteger(0)
+	}
+	if !count.IsInt64() || int64(value.BitLen())+count.Int64() > MAX_POWER_BITS {
+		return newError("value error: %s << %s is too large", left.Inspect(), right.Inspect())
+	}
+	return object.NewBigInteger(new(big.Int).Lsh(value, uint(count.Int64())))
+}
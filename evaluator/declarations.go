@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"node.go/ast"
+	"node.go/object"
+	"node.go/token"
+)
+
+// evalLetStatement declares a binding with let, const or var. Let and const
+// bind within the current block while var binds within the enclosing function,
+// where it has already been hoisted.
+func (e *Evaluator) evalLetStatement(node *ast.LetStatement, env *object.Environment) object.Object {
+	name := node.Name.Value
+	kind := bindingKind(node.Token.Type)
+	scope := env
+	if kind == object.VAR_BINDING {
+		scope = env.FunctionScope()
+	}
+	if err := checkRedeclaration(scope, name, kind); err != nil {
+		return err
+	}
+
+	if node.Value == nil {
+		// Redeclaring a var leaves its value alone
+		if _, ok := scope.Declared(name); kind != object.VAR_BINDING || !ok {
+			scope.Declare(name, object.NULL, kind)
+		}
+		return object.NULL
+	}
+
+	value := e.eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	if function, ok := value.(*object.Function); ok && function.Name == "" {
+		function.Name = name
+	}
+	scope.Declare(name, value, kind)
+	return object.NULL
+}
+
+// checkRedeclaration returns an error when the declaration of the name within
+// the scope would shadow a constant, or declare a constant over another name
+func checkRedeclaration(scope *object.Environment, name string, kind object.BindingKind) object.Object {
+	existing, ok := scope.Declared(name)
+	switch {
+	case !ok:
+		return nil
+	case existing == object.CONST_BINDING:
+		return newError("type error: cannot redeclare constant %s", name)
+	case kind == object.CONST_BINDING:
+		return newError("type error: cannot redeclare %s as a constant", name)
+	}
+	return nil
+}
+
+func bindingKind(tokenType token.TokenType) object.BindingKind {
+	switch tokenType {
+	case token.CONST:
+		return object.CONST_BINDING
+	case token.VAR:
+		return object.VAR_BINDING
+	}
+	return object.LET_BINDING
+}
+
+// functionVars returns the names the body of a function declares with var
+func (e *Evaluator) functionVars(body *ast.BlockStatement) []string {
+	names, ok := e.varNames[body]
+	if !ok {
+		names = ast.VarNames(body.Statements)
+		e.varNames[body] = names
+	}
+	return names
+}
+
+// hoistVars binds the names declared with var to null before the function or
+// the program declaring them runs, unless they are bound already, as
+// parameters for instance
+func hoistVars(names []string, env *object.Environment) {
+	for _, name := range names {
+		if _, ok := env.Declared(name); !ok {
+			env.Declare(name, object.NULL, object.VAR_BINDING)
+		}
+	}
+}
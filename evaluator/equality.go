@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"node.go/token"
+)
+
+// comparison is a pair of arrays or hashes being compared
+type comparison struct {
+	left  object.Object
+	right object.Object
+}
+
+// equal tells whether the objects are equal, as == does. Numbers are equal
+// when their values are, whatever their types, while other objects of distinct
+// types never are. Arrays are equal when their items are, and hashes when
+// they have equal values for the same keys, in any order. Functions are only
+// equal to themselves.
+func equal(left object.Object, right object.Object) bool {
+	return deepEqual(left, right, make(map[comparison]bool))
+}
+
+// deepEqual compares the items of arrays and hashes. Containers already
+// compared, or being compared, are taken to be equal: comparing structures that
+// contain themselves ends, and shared items are compared once. Any difference
+// is enough for the whole comparison to fail, whichever pair it is found in.
+func deepEqual(left object.Object, right object.Object, compared map[comparison]bool) bool {
+	if isNumber(left) && isNumber(right) {
+		return evalInfixOperatorExpression(token.EQ, left, right) == object.TRUE
+	}
+	if left.Type() != right.Type() {
+		return false
+	}
+
+	switch left := left.(type) {
+	case *object.Null:
+		return true
+	case *object.Boolean:
+		return left.Value == right.(*object.Boolean).Value
+	case *object.String:
+		return left.Value == right.(*object.String).Value
+	case *object.Array:
+		right := right.(*object.Array)
+		if left == right {
+			return true
+		}
+		if len(left.Items) != len(right.Items) {
+			return false
+		}
+		pair := comparison{left: left, right: right}
+		if compared[pair] {
+			return true
+		}
+		compared[pair] = true
+
+		for index, item := range left.Items {
+			if !deepEqual(item, right.Items[index], compared) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		right := right.(*object.Hash)
+		if left == right {
+			return true
+		}
+		if left.Len() != right.Len() {
+			return false
+		}
+		pair := comparison{left: left, right: right}
+		if compared[pair] {
+			return true
+		}
+		compared[pair] = true
+
+		for _, leftPair := range left.Pairs() {
+			rightPair, ok := right.Get(leftPair.Key.(object.Hashable))
+			if !ok || !deepEqual(leftPair.Value, rightPair.Value, compared) {
+				return false
+			}
+		}
+		return true
+	}
+	return left == right
+}
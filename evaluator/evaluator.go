@@ -2,6 +2,8 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"node.go/ast"
 	"node.go/object"
 	"node.go/token"
@@ -33,15 +35,21 @@ func evalBooleanLiteral(obj object.Object) object.Object {
 	return object.NULL
 }
 
-func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
+func (e *Evaluator) evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
+	hoistVars(ast.VarNames(stmts), env)
+
 	var result object.Object
 	for _, stmt := range stmts {
-		result = Eval(stmt, env)
+		result = e.eval(stmt, env)
 		if result != nil {
 			switch result := result.(type) {
 			case *object.Error:
 				return result
 			case *object.Return:
+				// return at the top level may still leave a call to be made
+				if tail, ok := result.Value.(*tailCall); ok {
+					return e.applyFunction(tail.function, tail.arguments, tail.call)
+				}
 				return result.Value
 			}
 		}
@@ -49,11 +57,11 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 	return result
 }
 
-func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.Object {
+func (e *Evaluator) evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, stmt := range stmts {
-		result = Eval(stmt, env)
+		result = e.eval(stmt, env)
 
 		if result != nil {
 			switch result.Type() {
@@ -61,6 +69,8 @@ func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.O
 				return result
 			case object.ERROR:
 				return result
+			case object.BREAK_TYPE, object.CONTINUE_TYPE:
+				return result
 			}
 		}
 	}
@@ -68,12 +78,114 @@ func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.O
 	return result
 }
 
+// evalLoopBody runs an iteration of a loop, telling whether the loop is over
+// along with the value to end it with
+func (e *Evaluator) evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := e.eval(body, env)
+	if result == nil {
+		return nil, false
+	}
+	switch result.Type() {
+	case object.BREAK_TYPE:
+		return object.NULL, true
+	case object.RETURN, object.ERROR:
+		return result, true
+	}
+	return nil, false
+}
+
+func (e *Evaluator) evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := e.eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return object.NULL
+		}
+		if result, over := e.evalLoopBody(node.Body, env); over {
+			return result
+		}
+	}
+}
+
+// evalForInStatement runs the body once per item of the iterable, binding the
+// loop variables in an environment of their own at each iteration
+func (e *Evaluator) evalForInStatement(node *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := e.eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var result object.Object
+	over := false
+	iterate := func(key object.Object, value object.Object) bool {
+		iterationEnv := object.NewBlockEnvironment(env)
+		if node.Key != nil {
+			iterationEnv.Set(node.Key.Value, key)
+		}
+		iterationEnv.Set(node.Value.Value, value)
+		result, over = e.evalLoopBody(node.Body, iterationEnv)
+		return !over
+	}
+
+	if err := Iterate(iterable, node.Key != nil, iterate); err != nil {
+		return locate(err, node.Iterable)
+	}
+	if over {
+		return result
+	}
+	return object.NULL
+}
+
+// Iterate calls next with each item of the iterable until it returns false.
+// Hashes give their keys as the value unless both keys and values are asked
+// for.
+func Iterate(iterable object.Object, both bool, next func(key object.Object, value object.Object) bool) object.Object {
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for index, item := range iterable.Items {
+			if !next(object.NewInteger(int64(index)), item) {
+				break
+			}
+		}
+	case *object.String:
+		index := 0
+		for _, char := range iterable.Value {
+			if !next(object.NewInteger(int64(index)), object.NewString(string(char))) {
+				break
+			}
+			index++
+		}
+	case *object.Hash:
+		for _, pair := range iterable.Pairs() {
+			value := pair.Value
+			if !both {
+				value = pair.Key
+			}
+			if !next(pair.Key, value) {
+				break
+			}
+		}
+	default:
+		return newError("type error: %s is not iterable", iterable.Type())
+	}
+	return nil
+}
+
 func evalMinusOperatorExpression(obj object.Object) object.Object {
-	if obj.Type() != object.INT {
-		return newError("unknown operator: -%s", obj.Type())
+	switch obj := obj.(type) {
+	case *object.Integer:
+		if obj.Value == math.MinInt64 {
+			return object.NewBigInteger(new(big.Int).Neg(big.NewInt(obj.Value)))
+		}
+		return &object.Integer{Value: -obj.Value}
+	case *object.BigInteger:
+		return object.NewBigInteger(new(big.Int).Neg(obj.Value))
+	case *object.Float:
+		return object.NewFloat(-obj.Value)
 	}
-	intObj, _ := obj.(*object.Integer)
-	return &object.Integer{Value: -intObj.Value}
+	return newError("unknown operator: -%s", obj.Type())
 }
 
 func evalIntegerToBoolean(value int64) *object.Boolean {
@@ -89,10 +201,13 @@ func evalBangOperatorExpression(obj object.Object) object.Object {
 	case object.BOOL:
 		return evalBooleanLiteral(obj)
 	case object.INT:
-		{
-			intObj, _ := obj.(*object.Integer)
+		// Big integers are never zero
+		if intObj, ok := obj.(*object.Integer); ok {
 			return evalIntegerToBoolean(intObj.Value)
 		}
+		return object.FALSE
+	case object.FLOAT:
+		return booleanToObject(obj.(*object.Float).Value == 0)
 	case object.NULL_TYPE:
 		return object.TRUE
 	}
@@ -105,14 +220,27 @@ func evalPrefixExpression(operator string, obj object.Object) object.Object {
 		return evalMinusOperatorExpression(obj)
 	case token.BANG:
 		return evalBangOperatorExpression(obj)
+	case token.BIT_NOT:
+		return evalBitwiseNotExpression(obj)
 	}
 	return newError("unknown operator: %s%s", operator, obj.Type())
 }
 
 func evalInfixIntegerExpression(
 	operator string, left object.Object, right object.Object) object.Object {
-	leftValue := left.(*object.Integer).Value
-	rightValue := right.(*object.Integer).Value
+	switch operator {
+	case token.POWER:
+		return evalIntegerPower(left, right)
+	case token.SHIFT_LEFT, token.SHIFT_RIGHT:
+		return evalShiftExpression(operator, left, right)
+	}
+	leftInt, leftOk := left.(*object.Integer)
+	rightInt, rightOk := right.(*object.Integer)
+	if !leftOk || !rightOk || overflows(operator, leftInt.Value, rightInt.Value) {
+		return evalInfixBigIntegerExpression(operator, left, right)
+	}
+	leftValue := leftInt.Value
+	rightValue := rightInt.Value
 	switch operator {
 	case token.PLUS:
 		return object.NewInteger(leftValue + rightValue)
@@ -127,6 +255,13 @@ func evalInfixIntegerExpression(
 			}
 			return object.NewInteger(leftValue / rightValue)
 		}
+	case token.PERCENT:
+		{
+			if 0 == rightValue {
+				return object.NULL
+			}
+			return object.NewInteger(leftValue % rightValue)
+		}
 	case token.EQ:
 		return booleanToObject(leftValue == rightValue)
 	case token.NOT_EQ:
@@ -139,26 +274,18 @@ func evalInfixIntegerExpression(
 		return booleanToObject(leftValue <= rightValue)
 	case token.GTE:
 		return booleanToObject(leftValue >= rightValue)
+	case token.BIT_AND:
+		return object.NewInteger(leftValue & rightValue)
+	case token.BIT_OR:
+		return object.NewInteger(leftValue | rightValue)
 	default:
 		return newError("unknown operator: %s%s", operator, object.INT)
 	}
-	return object.NULL
-}
-
-func evalInfixBooleanExpression(operator string, left object.Object, right object.Object) object.Object {
-	switch operator {
-	case token.EQ:
-		return booleanToObject(left == right)
-	case token.NOT_EQ:
-		return booleanToObject(left != right)
-	}
-	return newError("unknown operator: %s %s %s", object.BOOL, operator, object.BOOL)
 }
 
+// evalInfixStringExpression concatenates strings and orders them by code
+// point, as their UTF-8 encodings do byte by byte
 func evalInfixStringExpression(operator string, left object.Object, right object.Object) object.Object {
-	if operator != token.PLUS {
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
-	}
 	leftStr, ok := left.(*object.String)
 	if !ok {
 		return newError("type mismatch: %s %s %s. Bad left operand", left.Type(), operator, right.Type())
@@ -167,7 +294,19 @@ func evalInfixStringExpression(operator string, left object.Object, right object
 	if !ok {
 		return newError("type mismatch: %s %s %s. Bad left operand", left.Type(), operator, right.Type())
 	}
-	return object.NewString(leftStr.Value + rightStr.Value)
+	switch operator {
+	case token.PLUS:
+		return object.NewString(leftStr.Value + rightStr.Value)
+	case token.LT:
+		return booleanToObject(leftStr.Value < rightStr.Value)
+	case token.GT:
+		return booleanToObject(leftStr.Value > rightStr.Value)
+	case token.LTE:
+		return booleanToObject(leftStr.Value <= rightStr.Value)
+	case token.GTE:
+		return booleanToObject(leftStr.Value >= rightStr.Value)
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 }
 
 func evalInfixOperatorExpression(
@@ -175,8 +314,14 @@ func evalInfixOperatorExpression(
 	switch {
 	case left.Type() == object.INT && right.Type() == object.INT:
 		return evalInfixIntegerExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalInfixFloatExpression(operator, left, right)
+	case operator == token.EQ:
+		return booleanToObject(equal(left, right))
+	case operator == token.NOT_EQ:
+		return booleanToObject(!equal(left, right))
 	case left.Type() == object.BOOL && right.Type() == object.BOOL:
-		return evalInfixBooleanExpression(operator, left, right)
+		return newError("unknown operator: %s %s %s", object.BOOL, operator, object.BOOL)
 	case left.Type() == object.STRING && right.Type() == object.STRING:
 		return evalInfixStringExpression(operator, left, right)
 	case left.Type() != right.Type():
@@ -185,46 +330,51 @@ func evalInfixOperatorExpression(
 	return newError("unsupported types: %s %s %s", left.Type(), operator, right.Type())
 }
 
+// isTruthy tells whether the object counts as true in conditions and for the
+// logical operators. false, null and the numbers 0 and 0.0 are falsy, while
+// everything else is truthy, including empty strings, arrays and hashes.
 func isTruthy(obj object.Object) bool {
 	if obj == object.FALSE || obj == object.NULL {
 		return false
 	}
-	if obj.Type() == object.INT {
-		intObj, _ := obj.(*object.Integer)
+	if intObj, ok := obj.(*object.Integer); ok {
 		return intObj.Value != 0
 	}
+	if floatObj, ok := obj.(*object.Float); ok {
+		return floatObj.Value != 0
+	}
 	return true
 }
 
-func evalIfConditionalExpression(ifExpression *ast.IfExpression, env *object.Environment) object.Object {
-	evaluatedCondition := Eval(ifExpression.Condition, env)
+func (e *Evaluator) evalIfConditionalExpression(ifExpression *ast.IfExpression, env *object.Environment) object.Object {
+	evaluatedCondition := e.eval(ifExpression.Condition, env)
 	if isError(evaluatedCondition) {
 		return evaluatedCondition
 	}
 	if isTruthy(evaluatedCondition) {
-		return Eval(ifExpression.Consequence, env)
+		return e.eval(ifExpression.Consequence, env)
 	}
 	if ifExpression.Alternative != nil {
-		return Eval(ifExpression.Alternative, env)
+		return e.eval(ifExpression.Alternative, env)
 	}
 	return object.NULL
 }
 
-func evalIdentifierExpression(ident *ast.Identifier, env *object.Environment) object.Object {
+func (e *Evaluator) evalIdentifierExpression(ident *ast.Identifier, env *object.Environment) object.Object {
 	if value, ok := env.Get(ident.Value); ok {
 		return value
 	}
-	if builtin, ok := object.LookUpBuiltin(ident.Value); ok {
+	if builtin, ok := e.builtins.LookUp(ident.Value); ok {
 		return builtin
 	}
 	return newError("reference error: %s is not defined", ident.Value)
 }
 
-func evalExpressions(expressions []ast.Expression, env *object.Environment) []object.Object {
+func (e *Evaluator) evalExpressions(expressions []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
 	for _, expression := range expressions {
-		argument := Eval(expression, env)
+		argument := e.eval(expression, env)
 		if isError(argument) {
 			return []object.Object{argument}
 		}
@@ -234,10 +384,123 @@ func evalExpressions(expressions []ast.Expression, env *object.Environment) []ob
 	return result
 }
 
-func applyFunction(function object.Object, arguments []object.Object) object.Object {
+// MAX_TAIL_FRAMES bounds the frames of tail calls kept around for tracebacks,
+// so that tail recursion runs in constant space
+const MAX_TAIL_FRAMES = 64
+
+// TAIL_CALL is the type of the calls in tail position left to applyFunction
+const TAIL_CALL object.Type = "TAIL CALL"
+
+// tailCall is the result of a function whose last step is calling another
+// function. applyFunction makes that call in place of the returning function,
+// so that a chain of tail calls does not grow the Go stack.
+type tailCall struct {
+	function  *object.Function
+	arguments []object.Object
+	call      token.Position
+}
+
+func (tc *tailCall) Type() object.Type {
+	return TAIL_CALL
+}
+
+func (tc *tailCall) Inspect() string {
+	return "<tail call to " + functionName(tc.function) + ">"
+}
+
+// applyFunction calls the function and, should it fail, records the call in the
+// traceback of the error as it unwinds. Functions replaced by the ones they
+// tail call no longer run but are still reported in the traceback, up to
+// MAX_TAIL_FRAMES of them.
+func (e *Evaluator) applyFunction(function object.Object, arguments []object.Object, call token.Position) object.Object {
+	if err := e.enterCall(); err != nil {
+		return err
+	}
+	defer e.leaveCall()
+
+	var replaced []object.Frame
+	for {
+		if err := checkArguments(function, arguments); err != nil {
+			// Raised by the call itself rather than within the function
+			err.Pos = call
+			return traceReplaced(err, replaced)
+		}
+		result := e.callFunction(function, arguments)
+		tail, ok := result.(*tailCall)
+		if !ok {
+			if err, ok := result.(*object.Error); ok {
+				if frame, ok := newFrame(function, call); ok {
+					err.Trace = append(err.Trace, frame)
+				}
+				traceReplaced(err, replaced)
+			}
+			return result
+		}
+		frame, _ := newFrame(function, call)
+		replaced = pushTailFrame(replaced, frame)
+		function, arguments, call = tail.function, tail.arguments, tail.call
+	}
+}
+
+// checkArguments makes sure that the function is given an argument for each
+// of its parameters
+func checkArguments(function object.Object, arguments []object.Object) *object.Error {
+	funcObj, ok := function.(*object.Function)
+	if !ok || len(arguments) >= len(funcObj.Parameters) {
+		return nil
+	}
+	return object.NewError(fmt.Sprintf("type error: Expected %d arguments. Got %d",
+		len(funcObj.Parameters), len(arguments)))
+}
+
+// traceReplaced records the frames of the functions replaced by tail calls in
+// the traceback of the error
+func traceReplaced(err *object.Error, replaced []object.Frame) *object.Error {
+	for index := len(replaced) - 1; index >= 0; index-- {
+		err.Trace = append(err.Trace, replaced[index])
+	}
+	return err
+}
+
+// pushTailFrame records the frame unless it repeats the previous one, as with
+// self recursion. Once there are too many, the oldest frames but the first one
+// are dropped.
+func pushTailFrame(frames []object.Frame, frame object.Frame) []object.Frame {
+	if count := len(frames); count > 0 && frames[count-1] == frame {
+		return frames
+	}
+	if len(frames) >= MAX_TAIL_FRAMES {
+		frames = append(frames[:1], frames[2:]...)
+	}
+	return append(frames, frame)
+}
+
+func newFrame(function object.Object, call token.Position) (object.Frame, bool) {
+	switch function := function.(type) {
+	case *object.Function:
+		return object.Frame{Function: functionName(function), Call: call}, true
+	case *object.Builtin:
+		return object.Frame{Function: function.Name, Call: call}, true
+	}
+	return object.Frame{}, false
+}
+
+func functionName(function *object.Function) string {
+	if function.Name == "" {
+		return object.ANONYMOUS
+	}
+	return function.Name
+}
+
+func (e *Evaluator) callFunction(function object.Object, arguments []object.Object) object.Object {
 	if funcObj, ok := function.(*object.Function); ok {
 		extendedEnv := extendFunctionEnvironment(funcObj, arguments)
-		funcResult := evalBlockStatement(funcObj.Body.Statements, extendedEnv)
+		hoistVars(e.functionVars(funcObj.Body), extendedEnv)
+		funcResult := e.evalTailBlock(funcObj.Body.Statements, extendedEnv)
+		if funcResult == nil {
+			// Empty body
+			return object.NULL
+		}
 		return unwrapReturnValue(funcResult)
 	}
 	if builtin, ok := function.(*object.Builtin); ok {
@@ -246,9 +509,68 @@ func applyFunction(function object.Object, arguments []object.Object) object.Obj
 	return newError("not a function")
 }
 
+// evalTailBlock evaluates a block whose value is the one of the enclosing
+// function, leaving the call its last expression makes to applyFunction
+func (e *Evaluator) evalTailBlock(stmts []ast.Statement, env *object.Environment) object.Object {
+	if len(stmts) < 1 {
+		return nil
+	}
+	last := len(stmts) - 1
+	if result := e.evalBlockStatement(stmts[:last], env); result != nil {
+		switch result.Type() {
+		case object.RETURN, object.ERROR:
+			return result
+		}
+	}
+	if stmt, ok := stmts[last].(*ast.ExpressionStatement); ok {
+		return e.evalTailExpression(stmt.Expression, env)
+	}
+	return e.eval(stmts[last], env)
+}
+
+// evalTailExpression evaluates an expression in tail position: calls to
+// functions are returned as a tailCall rather than being made
+func (e *Evaluator) evalTailExpression(node ast.Expression, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		evalFunc := e.eval(node.Function, env)
+		if isError(evalFunc) {
+			return evalFunc
+		}
+		evalArgs := e.evalExpressions(node.Arguments, env)
+		if len(evalArgs) == 1 && isError(evalArgs[0]) {
+			return evalArgs[0]
+		}
+		if function, ok := evalFunc.(*object.Function); ok {
+			return &tailCall{function: function, arguments: evalArgs, call: node.Pos()}
+		}
+		// Builtins do not call back into the evaluator
+		return locate(e.applyFunction(evalFunc, evalArgs, node.Pos()), node)
+	case *ast.IfExpression:
+		condition := e.eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if isTruthy(condition) {
+			return e.evalTailBlock(node.Consequence.Statements, object.NewBlockEnvironment(env))
+		}
+		if node.Alternative != nil {
+			return e.evalTailBlock(node.Alternative.Statements, object.NewBlockEnvironment(env))
+		}
+		return object.NULL
+	case *ast.LogicalExpression:
+		left := e.eval(node.Left, env)
+		if isError(left) || decides(node.Operator, left) {
+			return left
+		}
+		return e.evalTailExpression(node.Right, env)
+	}
+	return e.eval(node, env)
+}
+
 func extendFunctionEnvironment(function *object.Function, arguments []object.Object) *object.Environment {
 	extendedEnv := object.NewEnclosedEnvironment(function.Env)
-	// TODO: Check if arguments are less than actual Parameters
+	// Extra arguments are ignored
 	for index, param := range function.Parameters {
 		extendedEnv.Set(param.Value, arguments[index])
 	}
@@ -263,13 +585,15 @@ func unwrapReturnValue(result object.Object) object.Object {
 	return result
 }
 
-func evalArrayIndexExpression(container *object.Array, indexExpression ast.Node, env *object.Environment) object.Object {
-	indexObj := Eval(indexExpression, env)
+func evalArrayIndexExpression(container *object.Array, indexObj object.Object) object.Object {
 	if indexObj.Type() != object.INT {
 		return newError("type error: %s cannot be used as index of %s",
 			indexObj.Type(), object.ARRAY)
 	}
-	index := indexObj.(*object.Integer)
+	index, ok := indexObj.(*object.Integer)
+	if !ok {
+		return object.NULL
+	}
 	indexValue := index.Value
 	if indexValue >= 0 && indexValue < int64(len(container.Items)) {
 		return container.Items[indexValue]
@@ -277,28 +601,44 @@ func evalArrayIndexExpression(container *object.Array, indexExpression ast.Node,
 	return object.NULL
 }
 
-func evalHashIndexExpression(container *object.Hash, indexExpression ast.Node, env *object.Environment) object.Object {
-	indexObj := Eval(indexExpression, env)
-	if isError(indexObj) {
-		return indexObj
+// evalStringIndexExpression returns the code point at the index as a string,
+// or null when the index is out of range
+func evalStringIndexExpression(container *object.String, indexObj object.Object) object.Object {
+	if indexObj.Type() != object.INT {
+		return newError("type error: %s cannot be used as index of %s",
+			indexObj.Type(), object.STRING)
+	}
+	index, ok := indexObj.(*object.Integer)
+	if !ok || index.Value < 0 {
+		return object.NULL
+	}
+	position := int64(0)
+	for _, char := range container.Value {
+		if position == index.Value {
+			return object.NewString(string(char))
+		}
+		position++
 	}
+	return object.NULL
+}
+
+func evalHashIndexExpression(container *object.Hash, indexObj object.Object) object.Object {
 	index, ok := indexObj.(object.Hashable)
 	if !ok {
 		return newError("value error: unhashable type as hash key: %s", indexObj.Type())
 	}
-	hashPair, ok := container.Pairs[index.HashKey()]
+	hashPair, ok := container.Get(index)
 	if !ok {
 		return object.NULL
 	}
 	return hashPair.Value
 }
 
-func evalHashLiteralExpression(astPairs map[ast.Expression]ast.Expression, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
-	hash := &object.Hash{Pairs: pairs}
+func (e *Evaluator) evalHashLiteralExpression(astPairs []ast.HashPair, env *object.Environment) object.Object {
+	hash := object.NewHash()
 
-	for keyNode, valueNode := range astPairs {
-		evalKey := Eval(keyNode, env)
+	for _, astPair := range astPairs {
+		evalKey := e.eval(astPair.Key, env)
 		if isError(evalKey) {
 			return evalKey
 		}
@@ -306,59 +646,140 @@ func evalHashLiteralExpression(astPairs map[ast.Expression]ast.Expression, env *
 		if !ok {
 			return newError("value error: unhashable type as hash key: %s", evalKey.Type())
 		}
-		evalValue := Eval(valueNode, env)
+		evalValue := e.eval(astPair.Value, env)
 		if isError(evalValue) {
 			return evalValue
 		}
-		hashed := key.HashKey()
-		pairs[hashed] = object.HashPair{Key: evalKey, Value: evalValue}
+		hash.Set(key, evalValue)
 	}
 
 	return hash
 }
 
-func evalIndexExpression(node *ast.IndexExpression, env *object.Environment) object.Object {
-	container := Eval(node.Container, env)
+func (e *Evaluator) evalIndexExpression(node *ast.IndexExpression, env *object.Environment) object.Object {
+	container := e.eval(node.Container, env)
+	if isError(container) {
+		return container
+	}
+	if !isIndexable(container) {
+		return newIndexableError(container)
+	}
+	index := e.eval(node.Index, env)
+	if isError(index) {
+		return index
+	}
+	return EvalIndex(container, index)
+}
+
+func isIndexable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Array, *object.Hash, *object.String:
+		return true
+	}
+	return false
+}
+
+func newIndexableError(container object.Object) object.Object {
+	return newError("type error: %s cannot be used as index expression", container.Type())
+}
+
+// The following operations are shared with the virtual machine so that both
+// engines agree on the semantics of the language.
+
+// EvalPrefix applies a prefix operator to an already evaluated operand
+func EvalPrefix(operator string, right object.Object) object.Object {
+	return evalPrefixExpression(operator, right)
+}
+
+// EvalInfix applies an infix operator to already evaluated operands
+func EvalInfix(operator string, left object.Object, right object.Object) object.Object {
+	return evalInfixOperatorExpression(operator, left, right)
+}
+
+// EvalIndex looks the index up in the container
+func EvalIndex(container object.Object, index object.Object) object.Object {
 	switch obj := container.(type) {
 	case *object.Array:
-		return evalArrayIndexExpression(obj, node.Index, env)
+		return evalArrayIndexExpression(obj, index)
 	case *object.Hash:
-		return evalHashIndexExpression(obj, node.Index, env)
-	default:
-		return newError("type error: %s cannot be used as index expression", container.Type())
+		return evalHashIndexExpression(obj, index)
+	case *object.String:
+		return evalStringIndexExpression(obj, index)
 	}
+	return newIndexableError(container)
 }
 
+// IsTruthy tells whether the object counts as true in a condition
+func IsTruthy(obj object.Object) bool {
+	return isTruthy(obj)
+}
+
+// Eval evaluates the node within the given environment with the default
+// options. Errors are tagged with the position of the innermost node they have
+// been raised from.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	return New(Options{}).Eval(node, env)
+}
+
+// Eval evaluates the node within the given environment, within the limits of
+// the evaluator. The limits on steps and call depth apply to each call on its
+// own.
+func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
+	e.steps = 0
+	e.depth = 0
+	return e.eval(node, env)
+}
+
+// Apply calls the function with the given arguments, within the limits of the
+// evaluator, as Eval does for a node
+func (e *Evaluator) Apply(function object.Object, arguments []object.Object) object.Object {
+	e.steps = 0
+	e.depth = 0
+	return e.applyFunction(function, arguments, token.Position{})
+}
+
+// eval evaluates the node as a step towards the limits of the evaluator
+func (e *Evaluator) eval(node ast.Node, env *object.Environment) object.Object {
+	if err := e.step(); err != nil {
+		return locate(err, node)
+	}
+	return locate(e.evalNode(node, env), node)
+}
+
+// locate tags the error with the position of the node, unless it already has
+// been located. Missing nodes, such as the value of a bare return, leave the
+// error to be located by the enclosing statement.
+func locate(result object.Object, node ast.Node) object.Object {
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() && node != nil {
+		err.Pos = node.Pos()
+	}
+	return result
+}
+
+func (e *Evaluator) evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
-		return evalProgram(node.Statements, env)
+		return e.evalProgram(node.Statements, env)
 	case *ast.BlockStatement:
-		return evalBlockStatement(node.Statements, env)
+		return e.evalBlockStatement(node.Statements, object.NewBlockEnvironment(env))
 	case *ast.LetStatement:
-		{
-			value := Eval(node.Value, env)
-			if isError(value) {
-				return value
-			}
-			env.Set(node.Name.Value, value)
-		}
+		return e.evalLetStatement(node, env)
 	case *ast.Identifier:
-		return evalIdentifierExpression(node, env)
+		return e.evalIdentifierExpression(node, env)
 	case *ast.ReturnStatement:
 		{
-			value := Eval(node.ReturnValue, env)
+			value := e.evalTailExpression(node.ReturnValue, env)
 			if isError(value) {
 				return value
 			}
 			return &object.Return{Value: value}
 		}
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return e.eval(node.Expression, env)
 	case *ast.PrefixExpression:
 		{
 			operator := node.Operator
-			right := Eval(node.Right, env)
+			right := e.eval(node.Right, env)
 			if isError(right) {
 				return right
 			}
@@ -366,11 +787,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 	case *ast.InfixExpression:
 		{
-			left := Eval(node.Left, env)
+			left := e.eval(node.Left, env)
 			if isError(left) {
 				return left
 			}
-			right := Eval(node.Right, env)
+			right := e.eval(node.Right, env)
 			if isError(right) {
 				return right
 			}
@@ -378,23 +799,39 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 	case *ast.CallExpression:
 		{
-			evalFunc := Eval(node.Function, env)
+			evalFunc := e.eval(node.Function, env)
 			if isError(evalFunc) {
 				return evalFunc
 			}
-			evalArgs := evalExpressions(node.Arguments, env)
+			evalArgs := e.evalExpressions(node.Arguments, env)
 			if len(evalArgs) == 1 && isError(evalArgs[0]) {
 				return evalArgs[0]
 			}
 
-			return applyFunction(evalFunc, evalArgs)
+			return e.applyFunction(evalFunc, evalArgs, node.Pos())
 		}
 	case *ast.IndexExpression:
-		return evalIndexExpression(node, env)
+		return e.evalIndexExpression(node, env)
+	case *ast.AssignExpression:
+		return e.evalAssignExpression(node, env)
+	case *ast.LogicalExpression:
+		return e.evalLogicalExpression(node, env)
+	case *ast.TemplateLiteral:
+		return e.evalTemplateLiteral(node, env)
 	case *ast.IfExpression:
-		return evalIfConditionalExpression(node, env)
+		return e.evalIfConditionalExpression(node, env)
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(node, env)
+	case *ast.ForInStatement:
+		return e.evalForInStatement(node, env)
+	case *ast.BreakStatement:
+		return object.BREAK
+	case *ast.ContinueStatement:
+		return object.CONTINUE
 	case *ast.IntegerLiteral:
 		return object.NewInteger(node.Value)
+	case *ast.FloatLiteral:
+		return object.NewFloat(node.Value)
 	case *ast.BooleanLiteral:
 		return booleanToObject(node.Value)
 	case *ast.FunctionLiteral:
@@ -402,13 +839,13 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.StringLiteral:
 		return object.NewString(node.Value)
 	case *ast.ArrayLiteral:
-		evalItems := evalExpressions(node.Items, env)
+		evalItems := e.evalExpressions(node.Items, env)
 		if len(evalItems) == 1 && isError(evalItems[0]) {
 			return evalItems[0]
 		}
 		return object.NewArray(evalItems)
 	case *ast.HashLiteral:
-		return evalHashLiteralExpression(node.Pairs, env)
+		return e.evalHashLiteralExpression(node.Pairs, env)
 	}
 
 	return object.NULL
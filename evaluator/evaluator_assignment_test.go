@@ -0,0 +1,102 @@
+package evaluator
+
+import "testing"
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"let a = 1; a = 2; a", 2},
+		{"let a = 1; a = a + 1", 2},
+		{"let a = 1; let b = 2; a = b = 3; a + b", 6},
+		{"let a = 10; a += 5; a", 15},
+		{"let a = 10; a -= 5; a", 5},
+		{"let a = 10; a *= 5; a", 50},
+		{"let a = 10; a /= 5; a", 2},
+		{"let a = 10; a %= 4; a", 2},
+		{`let s = "a"; s += "b"; s`, "ab"},
+		{"let a = 10; a /= 0; a", nil},
+		// The nearest binding is the one updated
+		{"let a = 1; let f = fn() { a = 2 }; f(); a", 2},
+		{"let a = 1; let f = fn() { let a = 5; a = 2 }; f(); a", 1},
+		{"let a = 1; let f = fn(a) { a = 2 }; f(0); a", 1},
+		{"let n = 0; for (x in [1, 2, 3]) { n += x }; n", 6},
+		{"let n = 0; while (n < 10) { n += 3 }; n", 12},
+		// Closures share the variables they capture
+		{`let counter = fn() { let n = 0; fn() { n += 1 } };
+		let next = counter(); next(); next(); next()`, 3},
+		{`let f = fn() { let n = 0; let inc = fn() { n += 1 }; inc(); inc(); n }; f()`, 2},
+		{`let f = fn() { let n = 0; let get = fn() { n }; n = 7; get() }; f()`, 7},
+		{`let f = fn() { let n = 1; let g = fn() { fn() { n *= 10 } }; g()(); n }; f()`, 10},
+		{`let f = fn() { let n = 1; let g = fn() { fn() { n } }; let h = g(); n = 4; h() }; f()`, 4},
+		// Each iteration binds the loop variable anew
+		{`let f = fn() { let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }) }; fs[0]() + fs[2]() }; f()`, 4},
+		// Local functions see the binding they are given
+		{`let f = fn() { let loop = fn(n) { if (n > 0) { loop(n - 1) } else { 0 } }; loop(5) }; f()`, 0},
+		{`let f = fn() { let g = fn() { g }; let h = g; g = 1; h() }; f()`, 1},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestIndexAssignExpression(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"let a = [1, 2, 3]; a[1] = 5; a[1]", 5},
+		{"let a = [1, 2, 3]; a[2] += 5", 8},
+		{"let a = [1, 2, 3]; let b = a; b[0] = 7; a[0]", 7},
+		{"let a = [[1], [2]]; a[1][0] *= 4; a[1][0]", 8},
+		{`let h = {"a": 1}; h["a"] = 2; h["a"]`, 2},
+		{`let h = {}; h["b"] = 3; h["b"]`, 3},
+		{`let h = {"n": 1}; h["n"] += 9; h["n"]`, 10},
+		{`let h = {}; h[true] = "t"; h[true]`, "t"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestAssignErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"a = 1", "reference error: a is not defined"},
+		{"a += 1", "reference error: a is not defined"},
+		{"let f = fn() { b = 1 }; f()", "reference error: b is not defined"},
+		{"for (x in [1]) { x }; x = 2", "reference error: x is not defined"},
+		{`let a = 1; a += "b"`, "type mismatch: INTEGER + STRING"},
+		{"let a = [1]; a[1] = 2", "index error: index 1 out of range for ARRAY of length 1"},
+		{"let a = [1]; a[-1] = 2", "index error: index -1 out of range for ARRAY of length 1"},
+		{`let a = [1]; a["x"] = 2`, "type error: STRING cannot be used as index of ARRAY"},
+		{"let h = {}; h[fn() {}] = 2", "value error: unhashable type as hash key: FUNCTION"},
+		{`let s = "abc"; s[0] = "x"`, "type error: STRING does not support index assignment"},
+		{`let h = {}; h["a"] += 1`, "type mismatch: NULL + INTEGER"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
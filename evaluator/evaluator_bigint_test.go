@@ -0,0 +1,104 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"testing"
+)
+
+func testInspect(t *testing.T, obj object.Object, expectedType object.Type, expected string) bool {
+	if obj.Type() != expectedType || obj.Inspect() != expected {
+		t.Errorf("expected %s %s. Got %s %s", expectedType, expected, obj.Type(), obj.Inspect())
+		return false
+	}
+	return true
+}
+
+func TestBigIntegerPromotion(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-4294967296 * 4294967296 * 2", "-36893488147419103232"},
+		{`
+		let factorial = fn(n) { if (n < 2) { 1 } else { n * factorial(n - 1) } };
+		factorial(30)`, "265252859812191058636308480000000"},
+		{`let x = 9223372036854775807; x += 1; x`, "9223372036854775808"},
+		{`int("123456789012345678901234567890")`, "123456789012345678901234567890"},
+		{"int(1e20)", "100000000000000000000"},
+	}
+
+	for _, test := range tests {
+		testInspect(t, testEval(t, test.code), object.INT, test.expected)
+	}
+}
+
+func TestBigIntegerDemotion(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{"9223372036854775807 + 1 - 1", 9223372036854775807},
+		{"(9223372036854775807 + 1) / 2", 4611686018427387904},
+		{"(4294967296 * 4294967296) % 7", 2},
+		{"(9223372036854775807 * 3) / 9223372036854775807", 3},
+		{"-(-(9223372036854775807 + 1)) - 1", 9223372036854775807},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		if _, ok := evaluated.(*object.Integer); !ok {
+			t.Errorf("%s: expected the result to be demoted. Got %T", test.code, evaluated)
+		}
+		testIntegerObject(t, evaluated, test.expected)
+	}
+}
+
+func TestBigIntegerComparisons(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{"9223372036854775807 + 1 > 9223372036854775807", true},
+		{"-9223372036854775807 - 2 < 0", true},
+		{"9223372036854775807 * 2 == 9223372036854775807 + 9223372036854775807", true},
+		{"9223372036854775807 * 2 != 9223372036854775807 * 2", false},
+		{"9223372036854775807 * 2 >= 1.5", true},
+		{"!(9223372036854775807 + 1)", false},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestBigIntegerHashKeys(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{`let h = {9223372036854775807 + 1: "big"}; h[9223372036854775807 + 1]`, "big"},
+		{`let h = {4294967296 * 4294967296: "big"}; h[18446744073709551616.0]`, "big"},
+		{`let h = {9223372036854775807 + 1: "big"}; h[0]`, nil},
+		{`let h = {(9223372036854775807 + 1) / 2: "demoted"}; h[4611686018427387904]`, "demoted"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		if expected, ok := test.expected.(string); ok {
+			testStringObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBigIntegerIndexes(t *testing.T) {
+	testNullObject(t, testEval(t, "[1, 2][9223372036854775807 + 1]"))
+	testErrorObject(t, testEval(t, "let a = [1]; a[9223372036854775807 + 1] = 2"),
+		"index error: index 9223372036854775808 out of range for ARRAY of length 1")
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"testing"
+)
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"~0", -1},
+		{"~5", -6},
+		{"~-1", 0},
+		{"-6 & 0xff", 250},
+		{"1 << 4", 16},
+		{"0xff >> 4", 15},
+		{"-16 >> 2", -4},
+		{"-1 >> 100", -1},
+		{"1 >> 100", 0},
+		{"0 << 1000000000", 0},
+		{"1 | 2 | 4 & 6", 7},
+		{"(1 | 2 | 4) & 6", 6},
+		{"1 << 2 + 1", 8},
+		{"2 ^ 3 >> 1", 4},
+		{"~2 ^ 2", -5},
+		{"let flags = 0; flags = flags | 1 << 3; flags & 8", 8},
+		{"(1 << 64) >> 63", 2},
+		{"(1 << 64 | 1) & 3", 1},
+		{"~(1 << 64) & 1", 1},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestShiftPromotion(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"1 << 63", "9223372036854775808"},
+		{"1 << 100", "1267650600228229401496703205376"},
+		{"-1 << 64", "-18446744073709551616"},
+		{"~(1 << 64)", "-18446744073709551617"},
+	}
+
+	for _, test := range tests {
+		testInspect(t, testEval(t, test.code), object.INT, test.expected)
+	}
+}
+
+func TestBitwiseErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"1 << -1", "value error: negative shift count -1"},
+		{"1 >> -2", "value error: negative shift count -2"},
+		{"1 << 10000000", "value error: 1 << 10000000 is too large"},
+		// As in C, & binds looser than ==
+		{"5 & 1 == 1", "type mismatch: INTEGER & BOOLEAN"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{`"a" | "b"`, "unknown operator: STRING | STRING"},
+		{"true & false", "unknown operator: BOOLEAN & BOOLEAN"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
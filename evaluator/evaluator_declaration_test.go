@@ -0,0 +1,105 @@
+package evaluator
+
+import "testing"
+
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"const a = 5; a", 5},
+		{"const a = 5; let f = fn() { a * 2 }; f()", 10},
+		// Blocks and functions may shadow constants
+		{"const a = 5; let f = fn() { let a = 1; a }; f()", 1},
+		{"const a = 5; if (true) { const a = 1; a }", 1},
+		{"const a = 5; if (true) { let a = 1 }; a", 5},
+		{"const f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; f(3)", 0},
+		{"let a = [1]; const b = a; b[0] = 2; a[0]", 2},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.code), test.expected.(int))
+	}
+}
+
+func TestBlockScopedLet(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"let a = 1; if (true) { let a = 2 }; a", 1},
+		{"let a = 1; if (true) { a = 2 }; a", 2},
+		{"let a = 1; if (false) { 0 } else { let a = 2; a }", 2},
+		{"let f = fn() { let a = 1; if (true) { let a = 2; return a } }; f()", 2},
+		{"let n = 0; while (n < 3) { let m = n; n = m + 1 }; n", 3},
+		// Each iteration binds the names anew, for closures to capture
+		{"let fs = []; let j = 0; while (j < 3) { let k = j; fs = push(fs, fn() { k }); j += 1 }; fs[0]()", 0},
+		{"let fs = []; let j = 0; while (j < 2) { let k = j; fs = push(fs, fn() { k += 10 }); j += 1 }; fs[0](); fs[0]() + fs[1]()", 31},
+		{"if (true) { let f = fn(n) { if (n < 1) { 0 } else { n + f(n - 1) } }; f(3) }", 6},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.code), test.expected.(int))
+	}
+}
+
+func TestVarStatement(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"var a = 5; a", 5},
+		{"var a = 1; var a = 2; a", 2},
+		{"var a = 1; var a; a", 1},
+		{"var a; a", nil},
+		// Declarations are hoisted to the top of the function
+		{"let x = a; var a = 1; x", nil},
+		{"var a = a; a", nil},
+		{"let f = fn() { let x = a; var a = 1; x }; f()", nil},
+		// and bound within the function rather than the block
+		{"if (true) { var a = 3 }; a", 3},
+		{"let f = fn() { if (true) { var a = 3 } a }; f()", 3},
+		{"let f = fn() { for (x in [1, 2, 3]) { var last = x } last }; f()", 3},
+		{"let f = fn() { var a = 1; if (true) { var a = 2 } a }; f()", 2},
+		{"let f = fn(a) { var a; a }; f(7)", 7},
+		{"let f = fn() { if (false) { var a = 1 } a }; f()", nil},
+		{"let f = fn() { var a = 1; fn() { var a = 2 }(); a }; f()", 1},
+		{"let a = 1; let f = fn() { var a = 2 }; f(); a", 1},
+		{"var a = 1; a = 2; a += 1; a", 3},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		if expected, ok := test.expected.(int); ok {
+			testIntegerObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDeclarationErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"const a = 1; a = 2", "type error: cannot assign to constant a"},
+		{"const a = 1; a += 2", "type error: cannot assign to constant a"},
+		{"const a = 1; let f = fn() { a = 2 }; f()", "type error: cannot assign to constant a"},
+		{"const a = 1; if (true) { a = 2 }", "type error: cannot assign to constant a"},
+		{"const a = 1; const a = 2", "type error: cannot redeclare constant a"},
+		{"const a = 1; let a = 2", "type error: cannot redeclare constant a"},
+		// The var is hoisted before the constant is declared
+		{"const a = 1; var a = 2", "type error: cannot redeclare a as a constant"},
+		{"let a = 1; const a = 2", "type error: cannot redeclare a as a constant"},
+		{"let f = fn(a) { const a = 2 }; f(1)", "type error: cannot redeclare a as a constant"},
+		{"var a = 1; const a = 2", "type error: cannot redeclare a as a constant"},
+		{"if (true) { let a = 1 }; a", "reference error: a is not defined"},
+		{"let f = fn() { if (true) { let a = 1 } a }; f()", "reference error: a is not defined"},
+		{"while (true) { let a = 1; break }; a", "reference error: a is not defined"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
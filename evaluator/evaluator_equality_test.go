@@ -0,0 +1,123 @@
+package evaluator
+
+import "testing"
+
+func TestStructuralEquality(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{`[1, 2] == [1, 2]`, true},
+		{`[1, 2] == [2, 1]`, false},
+		{`[1, 2] != [1, 2, 3]`, true},
+		{`[] == []`, true},
+		{`[1, [2, ["three"]]] == [1, [2, ["three"]]]`, true},
+		{`[1, [2, ["three"]]] == [1, [2, ["four"]]]`, false},
+		{`[1, 2.0, 10 ^ 20] == [1.0, 2, 10 ^ 20]`, true},
+		{`{"a": 1, "b": [2]} == {"a": 1, "b": [2]}`, true},
+		// The order of the pairs does not matter
+		{`{"a": 1, "b": 2} == {"b": 2, "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": 1} == {"a": 1, "b": 2}`, false},
+		{`{1: "x"} == {1.0: "x"}`, true},
+		{`{} == []`, false},
+		{`let a = [1]; let b = a; a == b`, true},
+		{`let a = [1]; let b = push(a, 2); a == b`, false},
+		{`"abc" == "abc"`, true},
+		{`"abc" != "abd"`, true},
+		{`true == true`, true},
+		{`[][0] == [][0]`, true},
+		{`[][0] != [][0]`, false},
+		{`[[][0]] == [[][0]]`, true},
+		{`let f = fn() { 1 }; f == f`, true},
+		{`fn() { 1 } == fn() { 1 }`, false},
+		{`len == len`, true},
+		{`len == head`, false},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestCrossTypeEquality(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{`1 == true`, false},
+		{`1 != true`, true},
+		{`1 == "1"`, false},
+		{`"" == [][0]`, false},
+		{`0 == [][0]`, false},
+		{`[][0] != false`, true},
+		{`[1] == {"0": 1}`, false},
+		{`[1] == 1`, false},
+		{`len == fn(x) { 1 }`, false},
+		{`1 == 1.0`, true},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestCyclicEquality(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{`let a = [1]; a[0] = a; a == a`, true},
+		{`let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b`, true},
+		{`let a = [1, 2]; a[0] = a; let b = [1, 3]; b[0] = b; a == b`, false},
+		{`let a = [0]; a[0] = a; let b = [[0]]; b[0][0] = b; a == b`, true},
+		{`let h = {}; h["self"] = h; let g = {}; g["self"] = g; h == g`, true},
+		{`let h = {"n": 1}; h["self"] = h; let g = {"n": 2}; g["self"] = g; h != g`, true},
+		{`let a = [1]; let h = {"a": a}; a[0] = h; [h] == [{"a": a}]`, true},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestStringOrdering(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"abc" > "abd"`, false},
+		{`"ab" < "abc"`, true},
+		{`"" < "a"`, true},
+		{`"B" < "a"`, true},
+		{`"a" <= "a"`, true},
+		{`"a" >= "b"`, false},
+		{`"é" > "z"`, true},
+		{`"😀" > "é"`, true},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestComparisonErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`"a" < 1`, "type mismatch: STRING < INTEGER"},
+		{`"a" - "b"`, "unknown operator: STRING - STRING"},
+		{`[1] < [2]`, "unsupported types: ARRAY < ARRAY"},
+		{`{} > {}`, "unsupported types: HASH > HASH"},
+		{`[][0] < [][0]`, "unsupported types: NULL < NULL"},
+		{`true < false`, "unknown operator: BOOLEAN < BOOLEAN"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
@@ -0,0 +1,184 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"testing"
+)
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	float, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("Object is not Float. Got %s", obj.Type())
+		if obj.Type() == object.ERROR {
+			t.Errorf("The error is %s", obj.Inspect())
+		}
+		return false
+	}
+	if expected != float.Value {
+		t.Errorf("Float.Value is not %g. Got %g", expected, float.Value)
+		return false
+	}
+	return true
+}
+
+func TestEvalFloatObject(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e-9", 1e-9},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"0.5 * 3", 1.5},
+		{"1 + 0.5", 1.5},
+		{"3 - 0.5", 2.5},
+		{"1 / 4.0", 0.25},
+		{"7.5 % 2", 1.5},
+		{"let x = 1; x += 0.5; x", 1.5},
+	}
+
+	for _, test := range tests {
+		testFloatObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestIntegerArithmeticStaysInteger(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{"7 / 2", 3},
+		{"-7 / 2", -3},
+		{"7 % 2", 1},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestFloatComparisons(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{"1.0 == 1", true},
+		{"1 == 1.0", true},
+		{"0.1 + 0.2 == 0.3", false},
+		{"1.5 != 1.5", false},
+		{"1 < 1.5", true},
+		{"2.5 > 3", false},
+		{"2.0 >= 2", true},
+		{"-0.5 <= -1", false},
+		{"!0.0", true},
+		{"!0.1", false},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestFloatDivisionByZero(t *testing.T) {
+	for _, code := range []string{"1.5 / 0", "1 / 0.0", "2.5 % 0.0"} {
+		testNullObject(t, testEval(t, code))
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"1.0", "1.0"},
+		{"2.5 * 2", "5.0"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"1e21", "1e+21"},
+		{"-0.0", "-0.0"},
+	}
+
+	for _, test := range tests {
+		if actual := testEval(t, test.code).Inspect(); actual != test.expected {
+			t.Errorf("%s: expected %s. Got %s", test.code, test.expected, actual)
+		}
+	}
+}
+
+func TestFloatHashKeys(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{`let h = {1: "one"}; h[1.0]`, "one"},
+		{`let h = {2.0: "two"}; h[2]`, "two"},
+		{`let h = {0.5: "half"}; h[0.5]`, "half"},
+		{`let h = {0.5: "half"}; h[1]`, nil},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		if expected, ok := test.expected.(string); ok {
+			testStringObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestConversionBuiltins(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"int(3.99)", 3},
+		{"int(-3.99)", -3},
+		{"int(7)", 7},
+		{`int(" 42 ")`, 42},
+		{"float(2)", 2.0},
+		{`float("2.5")`, 2.5},
+		{"float(1.5)", 1.5},
+		{"round(2.5)", 3},
+		{"round(-2.5)", -3},
+		{"round(2.4)", 2},
+		{"round(5)", 5},
+		{"round(3.14159, 2)", 3.14},
+		{"round(1234.5, -2)", 1200.0},
+		{"round(5, 1)", 5.0},
+		{"int(1e308 * 10)", "value error: cannot convert +Inf to INTEGER"},
+		{`int("1.5")`, `value error: cannot convert "1.5" to INTEGER`},
+		{`float("pi")`, `value error: cannot convert "pi" to FLOAT`},
+		{"int(true)", "type mismatch: Expected INTEGER, FLOAT or STRING. Got BOOLEAN"},
+		{`round("1")`, "type mismatch: Expected INTEGER or FLOAT. Got STRING"},
+		{"round(1.5, 0.5)", "type mismatch: Expected INTEGER. Got FLOAT"},
+		{"round()", "type error: Expected 1 or 2 arguments. Got 0"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestFloatErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`1.5 + "a"`, "type mismatch: FLOAT + STRING"},
+		{"1.5 + true", "type mismatch: FLOAT + BOOLEAN"},
+		{`1.5 ^ "2"`, "type mismatch: FLOAT ^ STRING"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
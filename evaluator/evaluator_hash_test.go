@@ -0,0 +1,39 @@
+package evaluator
+
+import "testing"
+
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`{"b": 1, "a": 2, "c": 3}`, `{'b': 1, 'a': 2, 'c': 3}`},
+		{`{3: "c", 1: "a", 2: "b"}`, `{3: 'c', 1: 'a', 2: 'b'}`},
+		// New keys go last, keys set again keep their place
+		{`let h = {"b": 1, "a": 2}; h["c"] = 3; h["b"] = 4; h`, `{'b': 4, 'a': 2, 'c': 3}`},
+		{`{"a": 1, "b": 2, "a": 3}`, `{'a': 3, 'b': 2}`},
+		{`let h = {1: "x"}; h[1.0] = "y"; h`, `{1.0: 'y'}`},
+		{`let keys = []; for (k in {"z": 1, "y": 2, "x": 3}) { keys = push(keys, k) }; keys`, `['z', 'y', 'x']`},
+		{`let values = []; for (k, v in {"z": 1, "y": 2}) { values = push(values, v) }; values`, `[1, 2]`},
+		{`let h = {}; for (k in ["q", "p", "r"]) { h[k] = len(h) }; h`, `{'q': 0, 'p': 1, 'r': 2}`},
+		{`json_decode("{\"b\": 1, \"a\": 2}")`, `{'b': 1, 'a': 2}`},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		if evaluated.Inspect() != test.expected {
+			t.Errorf("%s: expected %s. Got %s", test.code, test.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHashLiteralEvaluationOrder(t *testing.T) {
+	code := `let calls = [];
+	let log = fn(x) { calls = push(calls, x); x };
+	{log("a"): log(1), log("b"): log(2)};
+	calls`
+	evaluated := testEval(t, code)
+	if evaluated.Inspect() != "['a', 1, 'b', 2]" {
+		t.Errorf("expected keys and values to be evaluated in source order. Got %s", evaluated.Inspect())
+	}
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import "testing"
+
+func TestJSONEncode(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`json_encode([][0])`, `null`},
+		{`json_encode([true, false, 1, -2.5, 2.0, 10 ^ 20])`, `[true,false,1,-2.5,2.0,100000000000000000000]`},
+		{`json_encode("tab\t \"quoted\" <b> é")`, `"tab\t \"quoted\" <b> é"`},
+		// Keys are written in insertion order
+		{`json_encode({"b": 1, "a": [], "c": {}})`, `{"b":1,"a":[],"c":{}}`},
+		{`let h = {"z": 1}; h["a"] = 2; h["z"] = 3; json_encode(h)`, `{"z":3,"a":2}`},
+		{`json_encode({"b": [1, {"x": [][0]}], "a": "s"}, 2)`, "{\n  \"b\": [\n    1,\n    {\n      \"x\": null\n    }\n  ],\n  \"a\": \"s\"\n}"},
+		{`json_encode([1], "\t")`, "[\n\t1\n]"},
+		{`json_encode([], 2)`, `[]`},
+		// The same value twice is not a cycle
+		{`let a = [1]; json_encode([a, a])`, `[[1],[1]]`},
+	}
+
+	for _, test := range tests {
+		testStringObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestJSONDecode(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{`json_decode("42")`, 42},
+		{`json_decode(" -7 ")`, -7},
+		{`json_decode("[1, 2, 3]")[2]`, 3},
+		{`json_decode("{\"a\": {\"b\": [10, 20]}}")["a"]["b"][1]`, 20},
+		{`len(json_decode("{\"a\": 1, \"b\": 2, \"a\": 3}"))`, 2},
+		{`json_decode("{\"a\": 1, \"a\": 3}")["a"]`, 3},
+		{`json_decode("\"caf\\u00e9\"")`, "café"},
+		{`json_decode("[\"x\", true]")[0]`, "x"},
+		{`json_decode("null")`, nil},
+		{`json_decode("{\"a\": null}")["a"]`, nil},
+		{`json_decode("[]")[0]`, nil},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestJSONNumbers(t *testing.T) {
+	testFloatObject(t, testEval(t, `json_decode("2.5")`), 2.5)
+	testFloatObject(t, testEval(t, `json_decode("1e3")`), 1000)
+	testFloatObject(t, testEval(t, `json_decode("1.0")`), 1)
+	testInspect(t, testEval(t, `json_decode("123456789012345678901234567890")`), "INTEGER",
+		"123456789012345678901234567890")
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	code := `let value = {"name": "node", "tags": ["a", "b"], "size": 1.5, "count": 3, "nested": {"ok": true, "none": [][0]}};
+	json_encode(json_decode(json_encode(value)))`
+	testStringObject(t, testEval(t, code),
+		`{"name":"node","tags":["a","b"],"size":1.5,"count":3,"nested":{"ok":true,"none":null}}`)
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`json_encode()`, "type error: Expected 1 or 2 arguments. Got 0"},
+		{`json_encode(1, 2, 3)`, "type error: Expected 1 or 2 arguments. Got 3"},
+		{`json_encode(1, true)`, "type mismatch: Expected INTEGER or STRING. Got BOOLEAN"},
+		{`json_encode(1, -1)`, "value error: indent must be between 0 and 64. Got -1"},
+		{`json_encode(fn(x) { x })`, "type error: cannot encode FUNCTION as JSON"},
+		{`json_encode([len])`, "type error: cannot encode BUILTIN FUNCTION as JSON"},
+		{`json_encode({1: 2})`, "type error: cannot encode hash key of type INTEGER as JSON, keys must be strings"},
+		{`json_encode(1e308 * 10)`, "value error: cannot encode +Inf as JSON"},
+		{`let a = [1]; a[0] = a; json_encode(a)`, "value error: cannot encode ARRAY as JSON, it contains itself"},
+		{`let h = {}; h["self"] = h; json_encode(h)`, "value error: cannot encode HASH as JSON, it contains itself"},
+		{`json_decode(1)`, "type mismatch: Expected STRING. Got INTEGER"},
+		{`json_decode("")`, "value error: invalid JSON, unexpected end of input"},
+		{`json_decode("[1")`, "value error: invalid JSON, unexpected end of input"},
+		{`json_decode("[1}")`, "value error: invalid JSON, invalid character '}' after array element"},
+		{`json_decode("{1: 2}")`, "value error: invalid JSON, object member name must be a string"},
+		{`json_decode("[1] [2]")`, "value error: invalid JSON, unexpected data after the value"},
+		{`json_decode("1e999")`, "value error: cannot decode 1e999, it is out of range of FLOAT"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
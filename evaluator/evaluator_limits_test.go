@@ -0,0 +1,111 @@
+package evaluator
+
+import (
+	"context"
+	"node.go/lexer"
+	"node.go/object"
+	"node.go/parser"
+	"testing"
+	"time"
+)
+
+const INFINITE_RECURSION = `let f = fn(n) { 1 + f(n + 1) }; f(0)`
+const INFINITE_LOOP = `let f = fn(n) { f(n + 1) }; f(0)`
+
+func testEvalWithOptions(t *testing.T, code string, options Options) object.Object {
+	par := parser.New(lexer.New(code))
+	program := par.ParseProgram()
+	checkParserErrors(t, par)
+	return New(options).Eval(program, object.NewEnvironment())
+}
+
+func testLimitError(t *testing.T, obj object.Object, kind object.ErrorKind, message string) {
+	err, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("Object is not Error. Got %T(%+v)", obj, obj)
+	}
+	if !err.IsLimit() || err.Kind != kind {
+		t.Errorf("wrong error kind. Expected %s. Got %s", kind, err.Kind)
+	}
+	if err.Message != message {
+		t.Errorf("wrong error message. Expected %q. Got %q", message, err.Message)
+	}
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	tests := []struct {
+		options  Options
+		expected string
+	}{
+		{Options{}, "limit error: maximum call depth of 10000 exceeded"},
+		{Options{MaxCallDepth: 50}, "limit error: maximum call depth of 50 exceeded"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEvalWithOptions(t, INFINITE_RECURSION, test.options)
+		testLimitError(t, evaluated, object.CALL_DEPTH_EXCEEDED, test.expected)
+	}
+
+	evaluated := testEval(t, INFINITE_RECURSION)
+	testLimitError(t, evaluated, object.CALL_DEPTH_EXCEEDED, "limit error: maximum call depth of 10000 exceeded")
+}
+
+func TestCallDepthDoesNotLimitTailCalls(t *testing.T) {
+	code := `let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } }; count(20000)`
+	evaluated := testEvalWithOptions(t, code, Options{MaxCallDepth: 10})
+	testIntegerObject(t, evaluated, 0)
+}
+
+func TestUnlimitedCallDepth(t *testing.T) {
+	code := `let sum = fn(n) { if (n == 0) { 0 } else { n + sum(n - 1) } }; sum(20000)`
+	evaluated := testEvalWithOptions(t, code, Options{MaxCallDepth: -1})
+	testIntegerObject(t, evaluated, 200010000)
+}
+
+func TestStepsLimit(t *testing.T) {
+	evaluated := testEvalWithOptions(t, INFINITE_LOOP, Options{MaxSteps: 5000})
+	testLimitError(t, evaluated, object.STEPS_EXCEEDED, "limit error: maximum of 5000 steps exceeded")
+
+	evaluated = testEvalWithOptions(t, "1 + 2 * 3", Options{MaxSteps: 5000})
+	testIntegerObject(t, evaluated, 7)
+}
+
+func TestDeadline(t *testing.T) {
+	options := Options{Deadline: time.Now().Add(20 * time.Millisecond)}
+	evaluated := testEvalWithOptions(t, INFINITE_LOOP, options)
+	testLimitError(t, evaluated, object.DEADLINE_EXCEEDED, "limit error: deadline exceeded")
+}
+
+func TestContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	evaluated := testEvalWithOptions(t, INFINITE_LOOP, Options{Context: ctx})
+	testLimitError(t, evaluated, object.DEADLINE_EXCEEDED, "limit error: deadline exceeded")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	evaluated = testEvalWithOptions(t, INFINITE_LOOP, Options{Context: ctx})
+	testLimitError(t, evaluated, object.CANCELED, "limit error: evaluation canceled")
+}
+
+func TestLimitErrorsAreLocated(t *testing.T) {
+	evaluated := testEvalWithOptions(t, INFINITE_RECURSION, Options{MaxCallDepth: 3})
+	err := evaluated.(*object.Error)
+	if err.Pos.String() != "1:21" {
+		t.Errorf("error expected to be raised at 1:21. Got %s", err.Pos)
+	}
+	if len(err.Trace) != 3 {
+		t.Errorf("expected 3 frames. Got %d: %+v", len(err.Trace), err.Trace)
+	}
+}
+
+func TestLimitErrorsOnBareReturn(t *testing.T) {
+	// Whichever step the limit is reached at, including the missing value of
+	// the return statement, the error is located
+	for steps := 1; steps <= 12; steps++ {
+		evaluated := testEvalWithOptions(t, "let f = fn() { return; }; f()", Options{MaxSteps: steps})
+		if err, ok := evaluated.(*object.Error); ok && !err.Pos.IsValid() {
+			t.Errorf("error expected to be located after %d steps", steps)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package evaluator
+
+import "testing"
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		// The deciding operand is returned as is
+		{"1 && 2", 2},
+		{"0 && 2", 0},
+		{"0 || 2", 2},
+		{"3 || 2", 3},
+		{`"" || 1`, ""},
+		{"0.0 || 1", 1},
+		{"[] && 1", 1},
+		// Indexing out of range yields null
+		{"[][0] || 5", 5},
+		{"[][0] && 5", nil},
+		{"[][0] ?? 5", 5},
+		{"0 ?? 5", 0},
+		{"false ?? 5", false},
+		{`let h = {"a": 1}; h["b"] ?? h["a"]`, 1},
+		{"1 < 2 && 2 < 3", true},
+		{"let x = [][0]; x = x ?? 4; x", 4},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestLogicalOperatorsShortCircuit(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{"let n = 0; false && (n = 1); n", 0},
+		{"let n = 0; true || (n = 1); n", 0},
+		{"let n = 0; 1 ?? (n = 1); n", 0},
+		{"let n = 0; true && (n = 1); n", 1},
+		{"let n = 0; false || (n = 1); n", 1},
+		{"let n = 0; [][0] ?? (n = 1); n", 1},
+		{"let f = fn() { undefined }; 0 && f(); 7", 7},
+		{"let calls = 0; let f = fn() { calls += 1; true }; f() || f() || f(); calls", 1},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestLogicalOperatorsInLoops(t *testing.T) {
+	code := `
+	let i = 0;
+	let found = [][0];
+	let items = [3, 0, 5, 8];
+	while (i < len(items) && !found) {
+		if (items[i] > 4) { found = items[i] }
+		i += 1
+	}
+	found ?? -1`
+	testIntegerObject(t, testEval(t, code), 5)
+}
+
+func TestLogicalOperatorsInTailPosition(t *testing.T) {
+	code := `
+	let all = fn(items, i) { i >= len(items) || (items[i] > 0 && all(items, i + 1)) };
+	let items = [];
+	let n = 0;
+	while (n < 10000) { items = push(items, 1); n += 1 }
+	all(items, 0)`
+	testBooleanObject(t, testEval(t, code), true)
+}
+
+func TestLogicalOperatorErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"true && missing", "reference error: missing is not defined"},
+		{"missing || true", "reference error: missing is not defined"},
+		{"[][0] ?? -true", "unknown operator: -BOOLEAN"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
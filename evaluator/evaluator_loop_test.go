@@ -0,0 +1,76 @@
+package evaluator
+
+import "testing"
+
+func TestWhileStatement(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"let a = [1, 2, 3]; let sum = 0; while (len(a) > 0) { sum += pop(a) }; sum", 6},
+		{"let a = [1, 2, 3]; while (true) { if (pop(a) == 2) { break } }; len(a)", 1},
+		{`let a = [1, 2, 3, 4]; let small = 0;
+		while (len(a) > 0) { if (pop(a) > 2) { continue } small += 1 }; small`, 2},
+		{"while (false) { 1 }", nil},
+		{"let f = fn() { while (true) { return 5 } }; f()", 5},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		if expected, ok := test.expected.(int); ok {
+			testIntegerObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"let first = fn(arr) { for (x in arr) { if (x > 2) { return x } } }; first([1, 5, 3])", 5},
+		{"let at = fn(arr, n) { for (i, x in arr) { if (i == n) { return x } } }; at([7, 8, 9], 1)", 8},
+		{`let f = fn(s) { for (i, c in s) { if (i == 2) { return c } } }; f("abcd")`, "c"},
+		{`let f = fn(h) { for (k in h) { return k } }; f({"key": 1})`, "key"},
+		{`let f = fn(h) { for (k, v in h) { return v } }; f({"key": 1})`, 1},
+		{"for (x in []) { x }", nil},
+		{"let count = []; for (x in [1, 2, 3]) { push(count, x); break }; len(count)", 0},
+		// Breaking out of an inner loop goes on with the outer one
+		{`let a = [1, 2, 3, 4, 5, 6];
+		for (x in [1, 2]) { for (y in [3, 4, 5]) { if (y == 3) { continue } pop(a); break } }
+		len(a)`, 4},
+		// Each iteration binds the loop variables anew, for closures to capture
+		{"let fs = []; for (i in [1, 2, 3]) { fs = push(fs, fn() { i }) }; fs[0]() + fs[2]()", 4},
+		{"let fs = []; for (i, x in [5, 6]) { fs = push(fs, fn() { i * 10 + x }) }; fs[0]() + fs[1]()", 21},
+		{"let f = fn() { let fs = []; for (i in [1, 2, 3]) { fs = push(fs, fn() { i }) }; fs }; f()[0]()", 1},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestForInErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"for (x in 1) { x }", "type error: INTEGER is not iterable"},
+		{"for (x in [1]) { x }; x", "reference error: x is not defined"},
+		{"for (x in [1]) { x + true }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"testing"
+)
+
+func TestPowerOperator(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{"2 ^ 3", 8},
+		{"2 ^ 0", 1},
+		{"0 ^ 0", 1},
+		{"(-3) ^ 3", -27},
+		{"2 ^ 3 ^ 2", 512},
+		{"(2 ^ 3) ^ 2", 64},
+		{"-2 ^ 2", -4},
+		{"(-2) ^ 2", 4},
+		{"2 * 3 ^ 2", 18},
+		{"-1 ^ 9223372036854775807", -1},
+		{"(-1) ^ 9223372036854775807", -1},
+		{"(-1) ^ (9223372036854775807 + 1)", 1},
+		{"1 ^ (9223372036854775807 + 1)", 1},
+		{"2 ^ -1", 0.5},
+		{"2 ^ -2 ^ 2", 0.0625},
+		{"2.0 ^ 3", 8.0},
+		{"4 ^ 0.5", 2.0},
+		{"let x = 3; x = x ^ 2; x", 9},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestPowerOverflow(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"2 ^ 63", "9223372036854775808"},
+		{"2 ^ 70", "1180591620717411303424"},
+		{"(-2) ^ 63", "-9223372036854775808"},
+		{"(2 ^ 64) ^ 2", "340282366920938463463374607431768211456"},
+		{"10 ^ 30 / 10 ^ 29", "10"},
+	}
+
+	for _, test := range tests {
+		testInspect(t, testEval(t, test.code), object.INT, test.expected)
+	}
+}
+
+func TestPowerErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"0 ^ -1", "value error: 0 cannot be raised to a negative power"},
+		{"0.0 ^ -2", "value error: 0 cannot be raised to a negative power"},
+		{"2 ^ 9223372036854775807", "value error: 2 ^ 9223372036854775807 is too large"},
+		{"10 ^ 1000000", "value error: 10 ^ 1000000 is too large"},
+		{`2 ^ "3"`, "type mismatch: INTEGER ^ STRING"},
+		{"true ^ true", "unknown operator: BOOLEAN ^ BOOLEAN"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
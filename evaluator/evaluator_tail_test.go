@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"testing"
+)
+
+const MILLION = 1000000
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		// return of a call
+		{`let count = fn(n, acc) {
+			if (n == 0) { return acc }
+			return count(n - 1, acc + 1)
+		};
+		count(1000000, 0)`, MILLION},
+		// last expression of the body, through if branches
+		{`let count = fn(n, acc) {
+			if (n == 0) { acc } else { count(n - 1, acc + 1) }
+		};
+		count(1000000, 0)`, MILLION},
+		// return of a call within a branch
+		{`let count = fn(n) {
+			if (n > 0) { return count(n - 1) }
+			n
+		};
+		count(1000000)`, 0},
+		// mutual recursion
+		{`let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };
+		let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } };
+		if (even(1000000)) { 1 } else { 0 }`, 1},
+		// closures
+		{`let loop = fn(n, f) { if (n == 0) { return f(n) } loop(n - 1, f) };
+		loop(1000000, fn(x) { x + 1 })`, 1},
+		// top level return
+		{`let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } };
+		return count(1000000);`, 0},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		testIntegerObject(t, evaluated, test.expected)
+	}
+}
+
+func TestTailCallsAreNotMadeOutsideTailPosition(t *testing.T) {
+	evaluated := testEval(t, `let sum = fn(n) { if (n == 0) { 0 } else { n + sum(n - 1) } }; sum(100)`)
+	testIntegerObject(t, evaluated, 5050)
+}
+
+func TestTailCallTraceIsBounded(t *testing.T) {
+	code := `let even = fn(n) { if (n == 0) { n + true } else { odd(n - 1) } };
+let odd = fn(n) { even(n - 1) };
+even(1000000)`
+	evaluated := testEval(t, code)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("Object is not Error. Got %T(%+v)", evaluated, evaluated)
+	}
+	if len(err.Trace) > MAX_TAIL_FRAMES+1 {
+		t.Fatalf("expected at most %d frames. Got %d", MAX_TAIL_FRAMES+1, len(err.Trace))
+	}
+	outermost := err.Trace[len(err.Trace)-1]
+	if outermost.Function != "even" || outermost.Call.String() != "3:1" {
+		t.Errorf("unexpected outermost frame %+v", outermost)
+	}
+}
+
+func TestEmptyFunctionBody(t *testing.T) {
+	testNullObject(t, testEval(t, `fn() {}()`))
+}
@@ -0,0 +1,48 @@
+package evaluator
+
+import "testing"
+
+func TestTemplateLiteral(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`let name = "Ada"; "hello ${name}"`, "hello Ada"},
+		{`let age = 36; "you are ${age + 1}"`, "you are 37"},
+		{`"${1}${2}${3}"`, "123"},
+		{`"${1.5} ${true} ${[1, "a"]} ${[][0]}"`, "1.5 true [1, 'a'] null"},
+		{`let h = {"k": 1}; "${h["k"]}"`, "1"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`let f = fn(x) { "<${x}>" }; "${f("a")}${f("b")}"`, "<a><b>"},
+		{`"a\${b}"`, "a${b}"},
+		{`"cost: $5"`, "cost: $5"},
+		{`let s = ""; for (x in [1, 2, 3]) { s = "${s}${x}," }; s`, "1,2,3,"},
+	}
+
+	for _, test := range tests {
+		testStringObject(t, testEval(t, test.code), test.expected)
+	}
+}
+
+func TestTemplateLiteralEvaluationOrder(t *testing.T) {
+	code := `
+	let log = "";
+	let f = fn(x) { log += x; x };
+	"${f("a")}${f("b")}${f("c")}";
+	log`
+	testStringObject(t, testEval(t, code), "abc")
+}
+
+func TestTemplateLiteralErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`"hello ${name}"`, "reference error: name is not defined"},
+		{`"${1 + "a"}"`, "type mismatch: INTEGER + STRING"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
@@ -293,8 +293,8 @@ func TestErrorHandling(t *testing.T) {
 		expectedErrorMessage string
 	}{
 		{
-			"1 == true",
-			"type mismatch: INTEGER == BOOLEAN",
+			"1 < true",
+			"type mismatch: INTEGER < BOOLEAN",
 		},
 		{
 			"true > false",
@@ -314,7 +314,7 @@ func TestErrorHandling(t *testing.T) {
 		},
 		{
 			"1 > (false == 2)",
-			"type mismatch: BOOLEAN == INTEGER",
+			"type mismatch: INTEGER > BOOLEAN",
 		},
 		{
 			"!(true * true)",
@@ -322,11 +322,11 @@ func TestErrorHandling(t *testing.T) {
 		},
 		{
 			`if (true) {
-				if (1 != false) {
+				if (1 >= false) {
 					return 4
 				}
 			}`,
-			"type mismatch: INTEGER != BOOLEAN",
+			"type mismatch: INTEGER >= BOOLEAN",
 		},
 		{
 			"false <= 1; return 2;",
@@ -432,6 +432,14 @@ func TestErrorHandling(t *testing.T) {
 			`pop([], 2)`,
 			"type error: Expected 1 argument. Got 2",
 		},
+		{
+			`fn(a, b) { a }(1)`,
+			"type error: Expected 2 arguments. Got 1",
+		},
+		{
+			`let g = fn(a, b) { a }; let f = fn() { g(1) }; f()`,
+			"type error: Expected 2 arguments. Got 1",
+		},
 		{
 			`let dict = {fn(){}: 1}`,
 			"value error: unhashable type as hash key: FUNCTION",
@@ -920,12 +928,12 @@ func TestHashEvaluation(t *testing.T) {
 		t.Fatalf("hash evaluation. expected object to be %s. Got %s",
 			object.HASH, evaluated.Type())
 	}
-	if len(expected) != len(hash.Pairs) {
+	if len(expected) != hash.Len() {
 		t.Fatalf("Hash got a wrong number of pairs. Expected %d. Got %d",
-			len(expected), len(hash.Pairs))
+			len(expected), hash.Len())
 	}
 	for expectedHashKey, expectedHashPair := range expected {
-		actualHashPair, ok := hash.Pairs[expectedHashKey]
+		actualHashPair, ok := hash.Get(expectedHashPair.Key.(object.Hashable))
 		if !ok {
 			t.Fatalf("Hash did not contain HashKey %d for Key %s",
 				expectedHashKey.Value, expectedHashPair.Key.Inspect())
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"node.go/object"
+	"testing"
+)
+
+func TestErrorTrace(t *testing.T) {
+	code := `let inner = fn(x) {
+	x + true
+};
+let outer = fn(x) {
+	inner(x)
+};
+outer(1)`
+	evaluated := testEval(t, code)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("Object is not Error. Got %T(%+v)", evaluated, evaluated)
+	}
+	expectedFrames := []struct {
+		function string
+		call     string
+	}{
+		{"inner", "5:2"},
+		{"outer", "7:1"},
+	}
+	if len(err.Trace) != len(expectedFrames) {
+		t.Fatalf("expected %d frames. Got %d: %+v", len(expectedFrames), len(err.Trace), err.Trace)
+	}
+	for index, expected := range expectedFrames {
+		frame := err.Trace[index]
+		if frame.Function != expected.function {
+			t.Errorf("frame %d expected to be function %s. Got %s", index, expected.function, frame.Function)
+		}
+		if frame.Call.String() != expected.call {
+			t.Errorf("frame %d expected to be called at %s. Got %s", index, expected.call, frame.Call)
+		}
+	}
+	if err.Pos.String() != "2:2" {
+		t.Errorf("error expected to be raised at 2:2. Got %s", err.Pos)
+	}
+
+	expectedTraceback := `Traceback (most recent call last):
+  7:1, in <program>
+  5:2, in outer
+  2:2, in inner
+ERROR: type mismatch: INTEGER + BOOLEAN`
+	if err.Traceback() != expectedTraceback {
+		t.Errorf("unexpected traceback. Expected\n%s\nGot\n%s", expectedTraceback, err.Traceback())
+	}
+}
+
+func TestErrorTraceBuiltinAndAnonymousFrames(t *testing.T) {
+	evaluated := testEval(t, `fn(arr) { head(arr) }(1)`)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("Object is not Error. Got %T(%+v)", evaluated, evaluated)
+	}
+	if len(err.Trace) != 2 {
+		t.Fatalf("expected 2 frames. Got %d: %+v", len(err.Trace), err.Trace)
+	}
+	if err.Trace[0].Function != "head" || err.Trace[0].Call.String() != "1:11" {
+		t.Errorf("unexpected builtin frame %+v", err.Trace[0])
+	}
+	if err.Trace[1].Function != object.ANONYMOUS || err.Trace[1].Call.String() != "1:1" {
+		t.Errorf("unexpected anonymous frame %+v", err.Trace[1])
+	}
+}
+
+func TestFunctionsAreNamedAfterTheirFirstBinding(t *testing.T) {
+	evaluated := testEval(t, `let f = fn() {}; let g = f; g`)
+	function, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("Object is not Function. Got %T(%+v)", evaluated, evaluated)
+	}
+	if function.Name != "f" {
+		t.Fatalf("function expected to be named f. Got %s", function.Name)
+	}
+}
@@ -0,0 +1,53 @@
+package evaluator
+
+import "testing"
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected interface{}
+	}{
+		{`len("ñ")`, 1},
+		{`len("año 😀")`, 5},
+		{`byte_len("ñ")`, 2},
+		{`byte_len("año 😀")`, 9},
+		{`byte_len("")`, 0},
+		{`"héllo"[1]`, "é"},
+		{`"😀!"[0]`, "😀"},
+		{`"😀!"[1]`, "!"},
+		{`"abc"[3]`, nil},
+		{`"abc"[-1]`, nil},
+		{`let año = 2024; año + 1`, 2025},
+		{`let π = 3; π * 2`, 6},
+		{`let s = ""; for (c in "ñú") { s = c + s }; s`, "úñ"},
+		{`let n = 0; for (i, c in "😀a") { n = i }; n`, 1},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.code)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestUnicodeStringErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`"abc"["a"]`, "type error: STRING cannot be used as index of STRING"},
+		{`byte_len([])`, "type mismatch: Expected STRING. Got ARRAY"},
+		{`byte_len("a", "b")`, "type error: Expected 1 argument. Got 2"},
+		{`let s = "abc"; s[0] = "x"`, "type error: STRING does not support index assignment"},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.code), test.expected)
+	}
+}
@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"node.go/object"
+	"node.go/token"
+)
+
+// isNumber tells whether the object takes part in arithmetic
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INT || obj.Type() == object.FLOAT
+}
+
+// toFloat promotes a number to a float
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value
+	}
+	return obj.(*object.Float).Value
+}
+
+// evalInfixFloatExpression applies the operator to two numbers, at least one of
+// them a float. Integers are promoted, so the result of arithmetic is a float.
+func evalInfixFloatExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftValue := toFloat(left)
+	rightValue := toFloat(right)
+	switch operator {
+	case token.PLUS:
+		return object.NewFloat(leftValue + rightValue)
+	case token.ASTERISK:
+		return object.NewFloat(leftValue * rightValue)
+	case token.MINUS:
+		return object.NewFloat(leftValue - rightValue)
+	case token.SLASH:
+		if 0 == rightValue {
+			return object.NULL
+		}
+		return object.NewFloat(leftValue / rightValue)
+	case token.PERCENT:
+		if 0 == rightValue {
+			return object.NULL
+		}
+		return object.NewFloat(math.Mod(leftValue, rightValue))
+	case token.POWER:
+		return evalFloatPower(leftValue, rightValue)
+	case token.EQ:
+		return booleanToObject(leftValue == rightValue)
+	case token.NOT_EQ:
+		return booleanToObject(leftValue != rightValue)
+	case token.LT:
+		return booleanToObject(leftValue < rightValue)
+	case token.GT:
+		return booleanToObject(leftValue > rightValue)
+	case token.LTE:
+		return booleanToObject(leftValue <= rightValue)
+	case token.GTE:
+		return booleanToObject(leftValue >= rightValue)
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
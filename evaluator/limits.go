@@ -0,0 +1,99 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"node.go/ast"
+	"node.go/object"
+	"time"
+)
+
+// DEFAULT_MAX_CALL_DEPTH is the call depth allowed when none is given, low
+// enough for runaway recursion to be stopped before it exhausts the Go stack
+const DEFAULT_MAX_CALL_DEPTH = 10000
+
+// CHECK_INTERVAL is the number of steps between two checks of the deadline
+// and the context, which are too costly to check at every step
+const CHECK_INTERVAL = 1024
+
+// Options bounds the resources an evaluation may use, as needed to run
+// untrusted code. Going over a limit stops the evaluation with an
+// *object.Error whose Kind tells the limit apart from runtime errors.
+type Options struct {
+	// Nested function calls allowed. Zero stands for DEFAULT_MAX_CALL_DEPTH
+	// and negative values for no limit. Tail calls do not nest.
+	MaxCallDepth int
+	// Nodes evaluated, zero for no limit
+	MaxSteps int
+	// Time the evaluation has to end by, zero for no deadline
+	Deadline time.Time
+	// Stops the evaluation once done, nil for none
+	Context context.Context
+}
+
+// Evaluator walks the syntax tree within the limits of its options
+type Evaluator struct {
+	options Options
+
+	steps int
+	depth int
+
+	// Names declared with var by each function body, found once per body
+	varNames map[*ast.BlockStatement][]string
+
+	builtins object.Builtins
+}
+
+func New(options Options) *Evaluator {
+	if options.MaxCallDepth == 0 {
+		options.MaxCallDepth = DEFAULT_MAX_CALL_DEPTH
+	}
+	return &Evaluator{options: options, varNames: make(map[*ast.BlockStatement][]string)}
+}
+
+// SetBuiltins gives the programs builtins of their own, looked up after the
+// globals and before the shared builtins
+func (e *Evaluator) SetBuiltins(builtins object.Builtins) {
+	e.builtins = builtins
+}
+
+// step accounts for the evaluation of a node, returning an error once a limit
+// has been reached
+func (e *Evaluator) step() *object.Error {
+	e.steps++
+
+	if e.options.MaxSteps > 0 && e.steps > e.options.MaxSteps {
+		return object.NewLimitError(object.STEPS_EXCEEDED,
+			fmt.Sprintf("limit error: maximum of %d steps exceeded", e.options.MaxSteps))
+	}
+
+	if e.steps%CHECK_INTERVAL != 0 {
+		return nil
+	}
+	if !e.options.Deadline.IsZero() && !time.Now().Before(e.options.Deadline) {
+		return object.NewLimitError(object.DEADLINE_EXCEEDED, "limit error: deadline exceeded")
+	}
+	if e.options.Context != nil {
+		switch e.options.Context.Err() {
+		case nil:
+		case context.DeadlineExceeded:
+			return object.NewLimitError(object.DEADLINE_EXCEEDED, "limit error: deadline exceeded")
+		default:
+			return object.NewLimitError(object.CANCELED, "limit error: evaluation canceled")
+		}
+	}
+	return nil
+}
+
+func (e *Evaluator) enterCall() *object.Error {
+	if e.options.MaxCallDepth > 0 && e.depth >= e.options.MaxCallDepth {
+		return object.NewLimitError(object.CALL_DEPTH_EXCEEDED,
+			fmt.Sprintf("limit error: maximum call depth of %d exceeded", e.options.MaxCallDepth))
+	}
+	e.depth++
+	return nil
+}
+
+func (e *Evaluator) leaveCall() {
+	e.depth--
+}
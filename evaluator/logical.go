@@ -0,0 +1,34 @@
+package evaluator
+
+import (
+	"node.go/ast"
+	"node.go/object"
+	"node.go/token"
+)
+
+// decides tells whether the left operand of a logical operator is its result,
+// in which case the right operand is not evaluated
+func decides(operator string, left object.Object) bool {
+	switch operator {
+	case token.AND:
+		return !isTruthy(left)
+	case token.OR:
+		return isTruthy(left)
+	}
+	// ?? only falls back to the right operand on null
+	return left != object.NULL
+}
+
+// evalLogicalExpression evaluates &&, || and ??, which return the operand
+// deciding their result rather than a boolean, as in JavaScript:
+//
+//	0 || "none"   // "none", as 0 is falsy
+//	0 ?? "none"   // 0, as only null falls back
+//	[] && 1       // 1, as arrays are truthy, even empty ones
+func (e *Evaluator) evalLogicalExpression(node *ast.LogicalExpression, env *object.Environment) object.Object {
+	left := e.eval(node.Left, env)
+	if isError(left) || decides(node.Operator, left) {
+		return left
+	}
+	return e.eval(node.Right, env)
+}
@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"node.go/object"
+)
+
+// MAX_POWER_BITS bounds the size of the integers ^ computes, so that a program
+// cannot exhaust the memory with a single operation
+const MAX_POWER_BITS = 1 << 20
+
+// evalIntegerPower raises an integer to an integer power. Results are exact
+// and promoted to big integers when they overflow. Negative exponents yield
+// floats.
+func evalIntegerPower(left object.Object, right object.Object) object.Object {
+	base, _ := object.ToBigInt(left)
+	exponent, _ := object.ToBigInt(right)
+
+	if exponent.Sign() < 0 {
+		if base.Sign() == 0 {
+			return newError("value error: 0 cannot be raised to a negative power")
+		}
+		return object.NewFloat(math.Pow(toFloat(left), toFloat(right)))
+	}
+
+	if exponent.Sign() == 0 {
+		return object.NewInteger(1)
+	}
+	// 0, 1 and -1 stay small whatever the exponent
+	if base.CmpAbs(big.NewInt(1)) <= 0 {
+		if base.Sign() < 0 && exponent.Bit(0) == 0 {
+			return object.NewInteger(1)
+		}
+		return left
+	}
+
+	// As |base| >= 2, the result takes at least BitLen - 1 bits per unit of the
+	// exponent
+	if exponent.Cmp(big.NewInt(MAX_POWER_BITS)) > 0 || int64(base.BitLen()-1)*exponent.Int64() > MAX_POWER_BITS {
+		return newError("value error: %s ^ %s is too large", left.Inspect(), right.Inspect())
+	}
+	return object.NewBigInteger(new(big.Int).Exp(base, exponent, nil))
+}
+
+// evalFloatPower raises a number to a power, at least one of them a float
+func evalFloatPower(left float64, right float64) object.Object {
+	if left == 0 && right < 0 {
+		return newError("value error: 0 cannot be raised to a negative power")
+	}
+	return object.NewFloat(math.Pow(left, right))
+}
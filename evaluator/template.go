@@ -0,0 +1,23 @@
+package evaluator
+
+import (
+	"node.go/ast"
+	"node.go/object"
+	"strings"
+)
+
+// evalTemplateLiteral evaluates the expressions of the template from left to
+// right, inserting the display form of their values between its strings
+func (e *Evaluator) evalTemplateLiteral(node *ast.TemplateLiteral, env *object.Environment) object.Object {
+	var buffer strings.Builder
+	for index, expression := range node.Expressions {
+		value := e.eval(expression, env)
+		if isError(value) {
+			return value
+		}
+		buffer.WriteString(node.Strings[index])
+		buffer.WriteString(object.Display(value))
+	}
+	buffer.WriteString(node.Strings[len(node.Strings)-1])
+	return object.NewString(buffer.String())
+}
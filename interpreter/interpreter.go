@@ -0,0 +1,148 @@
+package interpreter
+
+import (
+	"fmt"
+	"node.go/engine"
+	"node.go/evaluator"
+	"node.go/lexer"
+	"node.go/object"
+	"node.go/parser"
+	"strings"
+)
+
+// SCRIPT is the file name programs run by Run are reported under
+const SCRIPT = "<script>"
+
+type Options struct {
+	// One of engine.Names, the default engine when empty
+	Engine string
+	// Limits each run and call within, only enforced by the evaluator engine
+	Limits evaluator.Options
+}
+
+// Interpreter embeds the language in Go programs. It runs programs one after
+// the other, each one seeing the globals and the functions defined by the
+// previous ones. Each interpreter has its own globals and builtins, so that
+// distinct interpreters may run side by side, even concurrently, though a
+// single one is not safe for concurrent use.
+type Interpreter struct {
+	engine   engine.Engine
+	builtins object.Builtins
+}
+
+func New(options Options) (*Interpreter, error) {
+	var eng engine.Engine
+	switch options.Engine {
+	case "", engine.EVALUATOR:
+		eng = engine.NewEvaluatorWithOptions(options.Limits)
+	case engine.VM:
+		eng = engine.NewVM()
+	default:
+		return nil, fmt.Errorf("unknown engine %q", options.Engine)
+	}
+	interp := &Interpreter{engine: eng, builtins: object.Builtins{}}
+	eng.SetBuiltins(interp.builtins)
+	return interp, nil
+}
+
+// RegisterBuiltin makes the function available to the programs of this
+// interpreter only. Registered builtins are looked up after the globals and
+// before the builtins of the same name shared by every interpreter. Unlike
+// globals, programs cannot assign to them.
+func (i *Interpreter) RegisterBuiltin(name string, fn object.BuiltinFunction) {
+	i.builtins[name] = &object.Builtin{Name: name, Fn: fn}
+}
+
+// SetGlobal binds a global for the programs run afterwards
+func (i *Interpreter) SetGlobal(name string, value object.Object) {
+	i.engine.Define(name, value)
+}
+
+// Global returns the value of a global left by the programs run so far
+func (i *Interpreter) Global(name string) (object.Object, bool) {
+	return i.engine.Global(name)
+}
+
+// Run parses and runs the program, returning its value. Syntax errors are
+// returned as a *SyntaxError and runtime errors as a *RuntimeError.
+func (i *Interpreter) Run(src string) (object.Object, error) {
+	par := parser.New(lexer.NewWithFilename(SCRIPT, src))
+	program := par.ParseProgram()
+	if len(par.Errors()) > 0 {
+		return nil, newSyntaxError(par.Diagnostics())
+	}
+	return result(i.engine.Run(program))
+}
+
+// Call calls the function of the given name, such as one defined by a
+// program, returning its value. Globals take precedence over the registered
+// builtins, and those over the shared ones, as within programs. Runtime errors are returned as a *RuntimeError.
+func (i *Interpreter) Call(fnName string, args ...object.Object) (object.Object, error) {
+	function, ok := i.engine.Global(fnName)
+	if !ok {
+		function, ok = i.lookUpBuiltin(fnName)
+	}
+	if !ok {
+		return nil, &RuntimeError{Err: object.NewError(fmt.Sprintf("reference error: %s is not defined", fnName))}
+	}
+	switch function.(type) {
+	case *object.Function, *object.Closure, *object.Builtin:
+	default:
+		return nil, &RuntimeError{Err: object.NewError(fmt.Sprintf("type error: %s is not a function. Got %s",
+			fnName, function.Type()))}
+	}
+	return result(i.engine.Call(function, args))
+}
+
+func (i *Interpreter) lookUpBuiltin(name string) (object.Object, bool) {
+	builtin, ok := i.builtins.LookUp(name)
+	return builtin, ok
+}
+
+func result(obj object.Object) (object.Object, error) {
+	if obj == nil {
+		return object.NULL, nil
+	}
+	if err, ok := obj.(*object.Error); ok {
+		return nil, &RuntimeError{Err: err}
+	}
+	return obj, nil
+}
+
+// SyntaxError holds the diagnostics that kept a program from being run
+type SyntaxError struct {
+	Diagnostics []parser.Diagnostic
+}
+
+func newSyntaxError(diagnostics []parser.Diagnostic) *SyntaxError {
+	var errors []parser.Diagnostic
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == parser.ERROR {
+			errors = append(errors, diagnostic)
+		}
+	}
+	return &SyntaxError{Diagnostics: errors}
+}
+
+func (e *SyntaxError) Error() string {
+	messages := make([]string, len(e.Diagnostics))
+	for index, diagnostic := range e.Diagnostics {
+		messages[index] = diagnostic.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// RuntimeError is an error raised while running a program or calling one of
+// its functions
+type RuntimeError struct {
+	Err *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Err.Message
+}
+
+// Traceback renders the calls that lead to the error, as the runner does
+func (e *RuntimeError) Traceback() string {
+	return e.Err.Traceback()
+}
@@ -0,0 +1,201 @@
+package interpreter
+
+import (
+	"fmt"
+	"node.go/engine"
+	"node.go/object"
+	"sync"
+	"testing"
+)
+
+func newInterpreter(t *testing.T, name string) *Interpreter {
+	interp, err := New(Options{Engine: name})
+	if err != nil {
+		t.Fatalf("cannot create interpreter: %s", err)
+	}
+	return interp
+}
+
+func testRun(t *testing.T, interp *Interpreter, src string, expected string) {
+	result, err := interp.Run(src)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %s", src, err)
+	}
+	if result.Inspect() != expected {
+		t.Errorf("%s: expected %s. Got %s", src, expected, result.Inspect())
+	}
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	for _, name := range engine.Names {
+		interp := newInterpreter(t, name)
+		interp.RegisterBuiltin("double", func(arguments ...object.Object) object.Object {
+			return object.NewInteger(arguments[0].(*object.Integer).Value * 2)
+		})
+		interp.RegisterBuiltin("len", func(arguments ...object.Object) object.Object {
+			return object.NewString("shadowed")
+		})
+
+		testRun(t, interp, "double(21)", "42")
+		testRun(t, interp, "len([])", "'shadowed'")
+		testRun(t, interp, "let f = fn(x) { double(x) + 1 }; f(1)", "3")
+	}
+}
+
+func TestConflictingBuiltins(t *testing.T) {
+	for _, name := range engine.Names {
+		first := newInterpreter(t, name)
+		second := newInterpreter(t, name)
+		first.RegisterBuiltin("greet", func(arguments ...object.Object) object.Object {
+			return object.NewString("first")
+		})
+		second.RegisterBuiltin("greet", func(arguments ...object.Object) object.Object {
+			return object.NewString("second")
+		})
+
+		testRun(t, first, "greet()", "'first'")
+		testRun(t, second, "greet()", "'second'")
+		if result, err := second.Call("greet"); err != nil || result.Inspect() != "'second'" {
+			t.Errorf("%s: expected greet to be called on the second interpreter. Got %v, %v", name, result, err)
+		}
+
+		// Registered builtins are not globals, programs cannot assign to them
+		if _, ok := first.Global("greet"); ok {
+			t.Errorf("%s: registered builtin found among the globals", name)
+		}
+		_, err := first.Run("greet = 1")
+		if err == nil || err.Error() != "reference error: greet is not defined" {
+			t.Errorf("%s: expected assigning to the builtin to fail. Got %v", name, err)
+		}
+		testRun(t, first, "greet()", "'first'")
+		// Globals are looked up first, as with the shared builtins
+		testRun(t, second, "let greet = fn() { \"global\" }; greet()", "'global'")
+		testRun(t, first, "greet()", "'first'")
+	}
+}
+
+func TestSetGlobal(t *testing.T) {
+	for _, name := range engine.Names {
+		interp := newInterpreter(t, name)
+		interp.SetGlobal("limit", object.NewInteger(10))
+
+		testRun(t, interp, "limit * 2", "20")
+		testRun(t, interp, "let total = limit + 1;", "null")
+		if total, ok := interp.Global("total"); !ok || total.Inspect() != "11" {
+			t.Errorf("%s: expected global total to be 11. Got %v", name, total)
+		}
+		if _, ok := interp.Global("missing"); ok {
+			t.Errorf("%s: undefined global found", name)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	for _, name := range engine.Names {
+		interp := newInterpreter(t, name)
+		testRun(t, interp, `
+let offset = 100;
+let add = fn(a, b) { a + b + offset };
+let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } };
+let fail = fn() { 1 + true };`, "null")
+
+		result, err := interp.Call("add", object.NewInteger(1), object.NewInteger(2))
+		if err != nil || result.Inspect() != "103" {
+			t.Errorf("%s: expected add(1, 2) to be 103. Got %v, %v", name, result, err)
+		}
+		result, err = interp.Call("fact", object.NewInteger(20))
+		if err != nil || result.Inspect() != "2432902008176640000" {
+			t.Errorf("%s: expected fact(20). Got %v, %v", name, result, err)
+		}
+		// Later programs see the globals changed by the call
+		testRun(t, interp, "offset = 0; add(1, 2)", "3")
+		result, err = interp.Call("len", object.NewString("abc"))
+		if err != nil || result.Inspect() != "3" {
+			t.Errorf("%s: expected len to fall back to the builtin. Got %v, %v", name, result, err)
+		}
+
+		errors := []struct {
+			fnName   string
+			args     []object.Object
+			expected string
+		}{
+			{"missing", nil, "reference error: missing is not defined"},
+			{"offset", nil, "type error: offset is not a function. Got INTEGER"},
+			{"add", []object.Object{object.NewInteger(1)}, "type error: Expected 2 arguments. Got 1"},
+			{"fail", nil, "type mismatch: INTEGER + BOOLEAN"},
+		}
+		for _, test := range errors {
+			_, err := interp.Call(test.fnName, test.args...)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("%s: expected error %q. Got %v", name, test.expected, err)
+			}
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, name := range engine.Names {
+		interp := newInterpreter(t, name)
+
+		_, err := interp.Run("let = 1")
+		syntaxError, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("%s: expected a *SyntaxError. Got %T", name, err)
+		}
+		if syntaxError.Error() != "<script>:1:5: expected identifier but found '='" {
+			t.Errorf("%s: unexpected syntax error %q", name, syntaxError.Error())
+		}
+
+		_, err = interp.Run("let f = fn() { x };\nf()")
+		runtimeError, ok := err.(*RuntimeError)
+		if !ok {
+			t.Fatalf("%s: expected a *RuntimeError. Got %T", name, err)
+		}
+		expected := "Traceback (most recent call last):\n" +
+			"  <script>:2:1, in <program>\n" +
+			"  <script>:1:16, in f\n" +
+			"ERROR: reference error: x is not defined"
+		if runtimeError.Traceback() != expected {
+			t.Errorf("%s: expected traceback %q. Got %q", name, expected, runtimeError.Traceback())
+		}
+	}
+
+	if _, err := New(Options{Engine: "jit"}); err == nil {
+		t.Errorf("expected an unknown engine to be refused")
+	}
+}
+
+func TestIsolatedInterpreters(t *testing.T) {
+	var wait sync.WaitGroup
+	for index := 0; index < 8; index++ {
+		wait.Add(1)
+		go func(index int) {
+			defer wait.Done()
+
+			interp := newInterpreter(t, engine.Names[index%len(engine.Names)])
+			interp.RegisterBuiltin("id", func(arguments ...object.Object) object.Object {
+				return object.NewInteger(int64(index))
+			})
+			if _, err := interp.Run("let total = 0; let add = fn(n) { total = total + n + id() };"); err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			for step := 0; step < 100; step++ {
+				if _, err := interp.Call("add", object.NewInteger(1)); err != nil {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+			}
+			total, _ := interp.Global("total")
+			if expected := fmt.Sprint(100 * (index + 1)); total.Inspect() != expected {
+				t.Errorf("interpreter %d: expected total %s. Got %s", index, expected, total.Inspect())
+			}
+		}(index)
+	}
+	wait.Wait()
+
+	interp := newInterpreter(t, engine.EVALUATOR)
+	if _, err := interp.Run("id()"); err == nil {
+		t.Errorf("builtin registered by another interpreter found")
+	}
+}
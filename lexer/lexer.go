@@ -1,64 +1,164 @@
 package lexer
 
-import "node.go/token"
+import (
+	"fmt"
+	"node.go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
-var WHITESPACES = map[byte]int{
+var WHITESPACES = map[rune]int{
 	'\n': 1,
 	'\r': 1,
 	' ':  1,
 	'\t': 1,
 }
 
+// Lexer splits UTF-8 encoded code into tokens, reading it a rune at a time.
+// Identifiers may hold any Unicode letter.
 type Lexer struct {
-	currentChar     byte
+	currentChar     rune
 	currentPosition int64
 	nextPosition    int64
 	input           string
 	inputLength     int64
+
+	filename string
+	line     int
+	column   int
+
+	comments []token.Token
+
+	// Depth of the braces opened within each template expression being lexed,
+	// innermost last
+	templates []int
 }
 
 func New(code string) *Lexer {
+	return NewWithFilename("", code)
+}
+
+// NewWithFilename creates a lexer whose token positions refer to the given file
+func NewWithFilename(filename string, code string) *Lexer {
 	lexer := Lexer{
 		currentPosition: 0,
 		nextPosition:    0,
 		input:           code,
 		inputLength:     int64(len(code)),
+		filename:        filename,
+		line:            1,
+		column:          1,
 	}
 	lexer.readChar()
 	return &lexer
 }
 
 func (l *Lexer) readChar() {
+	if l.nextPosition > 0 && l.currentPosition < l.inputLength {
+		if l.currentChar == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+	}
+
+	l.currentPosition = l.nextPosition
 	if l.nextPosition >= l.inputLength {
 		l.currentChar = 0
+		l.nextPosition += 1
+		return
+	}
+
+	char, size := utf8.DecodeRuneInString(l.input[l.nextPosition:])
+	l.currentChar = char
+	l.nextPosition += int64(size)
+}
+
+// invalidChar tells whether the current character is a byte that is not valid
+// UTF-8, as opposed to an encoded utf8.RuneError
+func (l *Lexer) invalidChar() bool {
+	return l.currentChar == utf8.RuneError && l.nextPosition-l.currentPosition == 1
+}
+
+// Line returns the source line containing the given position, without the line
+// terminator
+func (l *Lexer) Line(position token.Position) string {
+	offset := position.Offset
+	if offset > len(l.input) {
+		offset = len(l.input)
+	}
+	start := strings.LastIndexByte(l.input[:offset], '\n') + 1
+	end := strings.IndexByte(l.input[offset:], '\n')
+	if end < 0 {
+		end = len(l.input)
 	} else {
-		l.currentChar = l.input[l.nextPosition]
+		end += offset
 	}
+	return strings.TrimRight(l.input[start:end], "\r")
+}
 
-	l.currentPosition = l.nextPosition
-	l.nextPosition += 1
+// position returns the location of the current character
+func (l *Lexer) position() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Line:     l.line,
+		Column:   l.column,
+		Offset:   int(l.currentPosition),
+	}
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.nextPosition >= l.inputLength {
 		return 0
 	}
-	return l.input[l.nextPosition]
+	char, _ := utf8.DecodeRuneInString(l.input[l.nextPosition:])
+	return char
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+	if illegal, ok := l.skipTrivia(); ok {
+		return illegal
+	}
+
+	start := l.position()
+	tok := l.readToken()
+	tok.Start = start
+	if tok.Type == token.EOF {
+		tok.End = start
+	} else {
+		tok.End = l.position()
+	}
+
+	return tok
+}
 
-	l.consumeWhitespace()
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
 
 	switch l.currentChar {
 	// Delimiters
 	case ',':
 		tok = newToken(token.COMMA, l.currentChar)
 	case '{':
+		if len(l.templates) > 0 {
+			l.templates[len(l.templates)-1]++
+		}
 		tok = newToken(token.LBRACE, l.currentChar)
 		break
 	case '}':
+		if len(l.templates) > 0 {
+			top := len(l.templates) - 1
+			if l.templates[top] == 0 {
+				// The brace closes a template expression, the string goes on
+				l.templates = l.templates[:top]
+				tok = l.readString(true)
+				break
+			}
+			l.templates[top]--
+		}
 		tok = newToken(token.RBRACE, l.currentChar)
 		break
 	case '(':
@@ -80,9 +180,10 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.COLON, l.currentChar)
 		break
 	case '"':
-		l.readChar()
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		tok = l.readString(false)
+		break
+	case '`':
+		tok = l.readRawString()
 		break
 	// Operators
 	case '<':
@@ -92,6 +193,9 @@ func (l *Lexer) NextToken() token.Token {
 				l.readChar()
 				tok.Type = token.LTE
 				tok.Literal = string(ch) + string(l.currentChar)
+			} else if l.peekChar() == '<' {
+				l.readChar()
+				tok = token.Token{Type: token.SHIFT_LEFT, Literal: token.SHIFT_LEFT}
 			} else {
 				tok = newToken(token.LT, l.currentChar)
 			}
@@ -104,6 +208,9 @@ func (l *Lexer) NextToken() token.Token {
 				l.readChar()
 				tok.Type = token.GTE
 				tok.Literal = string(ch) + string(l.currentChar)
+			} else if l.peekChar() == '>' {
+				l.readChar()
+				tok = token.Token{Type: token.SHIFT_RIGHT, Literal: token.SHIFT_RIGHT}
 			} else {
 				tok = newToken(token.GT, l.currentChar)
 			}
@@ -120,23 +227,35 @@ func (l *Lexer) NextToken() token.Token {
 		}
 		break
 	case '/':
-		tok = newToken(token.SLASH, l.currentChar)
+		tok = l.readAssignableOperator(token.SLASH, token.SLASH_ASSIGN)
 		break
 	case '+':
-		tok = newToken(token.PLUS, l.currentChar)
+		tok = l.readAssignableOperator(token.PLUS, token.PLUS_ASSIGN)
 		break
 	case '-':
-		tok = newToken(token.MINUS, l.currentChar)
+		tok = l.readAssignableOperator(token.MINUS, token.MINUS_ASSIGN)
 		break
 	case '*':
-		tok = newToken(token.ASTERISK, l.currentChar)
+		tok = l.readAssignableOperator(token.ASTERISK, token.ASTERISK_ASSIGN)
 		break
 	case '%':
-		tok = newToken(token.PERCENT, l.currentChar)
+		tok = l.readAssignableOperator(token.PERCENT, token.PERCENT_ASSIGN)
 		break
 	case '^':
 		tok = newToken(token.POWER, l.currentChar)
 		break
+	case '&':
+		tok = l.readDoubledOperator(token.BIT_AND, token.AND)
+		break
+	case '|':
+		tok = l.readDoubledOperator(token.BIT_OR, token.OR)
+		break
+	case '?':
+		tok = l.readDoubledOperator(token.ILLEGAL, token.NULLISH)
+		break
+	case '~':
+		tok = newToken(token.BIT_NOT, l.currentChar)
+		break
 	case '=':
 		{
 			ch := l.currentChar
@@ -156,31 +275,254 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Type = token.EOF
 		break
 	default:
-		if isDigit(l.currentChar) {
-			return token.Token{Type: token.INT, Literal: l.readNumber()}
+		if isDigit(l.currentChar) || (l.currentChar == '.' && isDigit(l.peekChar())) {
+			return l.readNumber()
 		} else if isLetter(l.currentChar) {
 			tokenLiteral := l.readWord()
 			tokenType := token.LookupKeyword(tokenLiteral)
 			return token.Token{Type: tokenType, Literal: tokenLiteral}
+		} else if l.invalidChar() {
+			tok = newIllegalToken("invalid UTF-8 encoding")
+			break
 		}
-		tok = newToken(token.ILLEGAL, l.currentChar)
+		tok = newIllegalToken("unexpected character %q", l.currentChar)
 	}
 	l.readChar()
 	return tok
 }
 
+// readAssignableOperator reads an operator, or its compound assignment when it
+// is followed by '='
+func (l *Lexer) readAssignableOperator(operator token.TokenType, assignment token.TokenType) token.Token {
+	if l.peekChar() != '=' {
+		return newToken(operator, l.currentChar)
+	}
+	ch := l.currentChar
+	l.readChar()
+	return token.Token{Type: assignment, Literal: string(ch) + string(l.currentChar)}
+}
+
+// readDoubledOperator reads an operator made of the current character twice,
+// such as &&, or else the operator made of the character alone. Characters
+// which are no operator alone, given as ILLEGAL, have to be doubled.
+func (l *Lexer) readDoubledOperator(single token.TokenType, doubled token.TokenType) token.Token {
+	if l.peekChar() == l.currentChar {
+		l.readChar()
+		return token.Token{Type: doubled, Literal: string(doubled)}
+	}
+	if single == token.ILLEGAL {
+		return newIllegalToken("unexpected character %q", l.currentChar)
+	}
+	return newToken(single, l.currentChar)
+}
+
+// Comments returns the comments skipped so far, as COMMENT tokens whose literal
+// is the whole comment including its delimiters
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+// skipTrivia skips the whitespaces and comments preceding the next token. An
+// unterminated block comment is returned as an ILLEGAL token.
+func (l *Lexer) skipTrivia() (token.Token, bool) {
+	for {
+		l.consumeWhitespace()
+		if l.currentChar != '/' || (l.peekChar() != '/' && l.peekChar() != '*') {
+			return token.Token{}, false
+		}
+
+		start := l.position()
+		terminated := true
+		if l.peekChar() == '/' {
+			l.readLineComment()
+		} else {
+			terminated = l.readBlockComment()
+		}
+		comment := token.Token{
+			Type:    token.COMMENT,
+			Literal: l.input[start.Offset:l.currentPosition],
+			Start:   start,
+			End:     l.position(),
+		}
+		if !terminated {
+			illegal := newIllegalToken("unterminated block comment")
+			illegal.Start, illegal.End = comment.Start, comment.End
+			return illegal, true
+		}
+		l.comments = append(l.comments, comment)
+	}
+}
+
+// readLineComment reads a // comment up to the end of the line
+func (l *Lexer) readLineComment() {
+	for l.currentChar != '\n' && l.currentChar != 0 {
+		l.readChar()
+	}
+}
+
+// readBlockComment reads a /* */ comment, which may nest other block comments,
+// telling whether it is terminated
+func (l *Lexer) readBlockComment() bool {
+	depth := 0
+	for l.currentChar != 0 {
+		if l.currentChar == '/' && l.peekChar() == '*' {
+			depth++
+			l.readChar()
+		} else if l.currentChar == '*' && l.peekChar() == '/' {
+			depth--
+			l.readChar()
+		}
+		l.readChar()
+		if depth == 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *Lexer) consumeWhitespace() {
 	for isWhiteSpace(l.currentChar) {
 		l.readChar()
 	}
 }
 
-func (l *Lexer) readNumber() string {
+// Bases of the integer literals by the letter following their leading 0
+var integerPrefixes = map[rune]struct {
+	base int
+	name string
+}{
+	'x': {16, "hexadecimal"},
+	'X': {16, "hexadecimal"},
+	'o': {8, "octal"},
+	'O': {8, "octal"},
+	'b': {2, "binary"},
+	'B': {2, "binary"},
+}
+
+// readNumber reads an integer, or a float when it has a fractional part or an
+// exponent: 3.14, .5, 1e-9. Integers may also be written in hexadecimal, octal
+// or binary: 0xff, 0o755, 0b1010. Underscores may separate digits: 1_000_000.
+// An exponent without digits, or letters following the number, make the
+// literal invalid.
+func (l *Lexer) readNumber() token.Token {
+	if _, ok := integerPrefixes[l.peekChar()]; ok && l.currentChar == '0' {
+		return l.readPrefixedInteger()
+	}
+
 	pos := l.currentPosition
-	for isDigit(l.currentChar) {
+	tokenType := token.TokenType(token.INT)
+	l.readDigits()
+	if l.currentChar == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
 		l.readChar()
+		l.readDigits()
 	}
-	return l.input[pos:l.currentPosition]
+	if l.currentChar == 'e' || l.currentChar == 'E' {
+		exponent := l.nextPosition
+		if exponent < l.inputLength && (l.input[exponent] == '+' || l.input[exponent] == '-') {
+			exponent++
+		}
+		if exponent >= l.inputLength || !isDigit(rune(l.input[exponent])) {
+			for l.nextPosition < exponent {
+				l.readChar()
+			}
+			l.readChar()
+			return newIllegalToken("invalid number literal '%s', its exponent has no digits",
+				l.input[pos:l.currentPosition])
+		}
+		tokenType = token.FLOAT
+		for l.nextPosition <= exponent {
+			l.readChar()
+		}
+		l.readDigits()
+	}
+	// Letters cannot follow a number, as in 1x, rather than starting an
+	// identifier
+	if isLetter(l.currentChar) {
+		char := l.currentChar
+		for isLetter(l.currentChar) || isDigit(l.currentChar) {
+			l.readChar()
+		}
+		return newIllegalToken("invalid character %q in number literal '%s'", char, l.input[pos:l.currentPosition])
+	}
+
+	literal := l.input[pos:l.currentPosition]
+	groups := strings.FieldsFunc(literal, func(char rune) bool {
+		return !isDigit(char) && char != '_'
+	})
+	for _, group := range groups {
+		if !validSeparators(group) {
+			return newIllegalToken("invalid number literal '%s', '_' must separate successive digits", literal)
+		}
+	}
+	return token.Token{Type: tokenType, Literal: literal}
+}
+
+// readPrefixedInteger reads an integer written with a base prefix such as 0x.
+// Any letter or digit following the prefix is read as part of the literal, so
+// that invalid digits are reported rather than starting another token. So is a
+// fractional part, which only decimal numbers may have.
+func (l *Lexer) readPrefixedInteger() token.Token {
+	pos := l.currentPosition
+	l.readChar()
+	prefix := integerPrefixes[l.currentChar]
+	l.readChar()
+
+	start := l.currentPosition
+	for isLetter(l.currentChar) || isDigit(l.currentChar) {
+		l.readChar()
+	}
+	digits := l.input[start:l.currentPosition]
+
+	if l.currentChar == '.' {
+		l.readChar()
+		for isLetter(l.currentChar) || isDigit(l.currentChar) {
+			l.readChar()
+		}
+		return newIllegalToken("invalid %s literal '%s', only decimal numbers can have a fractional part",
+			prefix.name, l.input[pos:l.currentPosition])
+	}
+	literal := l.input[pos:l.currentPosition]
+
+	if strings.Trim(digits, "_") == "" {
+		return newIllegalToken("invalid %s literal '%s', it has no digits", prefix.name, literal)
+	}
+	for _, char := range digits {
+		if char != '_' && digitValue(char) >= prefix.base {
+			return newIllegalToken("invalid digit %q in %s literal '%s'", char, prefix.name, literal)
+		}
+	}
+	if !validSeparators(digits) {
+		return newIllegalToken("invalid %s literal '%s', '_' must separate successive digits", prefix.name, literal)
+	}
+	return token.Token{Type: token.INT, Literal: literal}
+}
+
+// readDigits reads decimal digits along with the underscores separating them
+func (l *Lexer) readDigits() {
+	for isDigit(l.currentChar) || l.currentChar == '_' {
+		l.readChar()
+	}
+}
+
+// validSeparators tells whether the underscores among the digits each sit
+// between two digits
+func validSeparators(digits string) bool {
+	return !strings.HasPrefix(digits, "_") && !strings.HasSuffix(digits, "_") &&
+		!strings.Contains(digits, "__")
+}
+
+// digitValue returns the value of a digit in bases up to 36
+func digitValue(char rune) int {
+	switch {
+	case isDigit(char):
+		return int(char - '0')
+	case char >= 'a' && char <= 'z':
+		return int(char-'a') + 10
+	case char >= 'A' && char <= 'Z':
+		return int(char-'A') + 10
+	}
+	return 36
 }
 
 func (l *Lexer) readWord() string {
@@ -191,31 +533,154 @@ func (l *Lexer) readWord() string {
 	return l.input[pos:l.currentPosition]
 }
 
-func (l *Lexer) readString() string {
-	pos := l.currentPosition
+// readString reads a double quoted string, from its opening quote up to its
+// closing one, and decodes its escape sequences. The string is read to its end
+// even when an escape sequence is invalid, so that lexing resumes after it.
+//
+// A string holding ${ is a template string, read in parts: the part up to ${
+// is its head, and the expression is then lexed up to the matching }, where
+// the string is continued. Continued parts are the middles ending with ${ and
+// the tail ending with the closing quote.
+func (l *Lexer) readString(continued bool) token.Token {
+	var buffer strings.Builder
+	var invalid string
 
-	for l.currentChar != '"' {
+	tokenType := token.TokenType(token.STRING)
+	if continued {
+		tokenType = token.TEMPLATE_TAIL
+	}
+
+	for l.readChar(); l.currentChar != '"'; l.readChar() {
+		switch l.currentChar {
+		case 0:
+			return newIllegalToken("unterminated string")
+		case utf8.RuneError:
+			if l.invalidChar() && invalid == "" {
+				invalid = "invalid UTF-8 encoding in string"
+			}
+			buffer.WriteRune(l.currentChar)
+		case '$':
+			if l.peekChar() != '{' {
+				buffer.WriteRune(l.currentChar)
+				continue
+			}
+			l.readChar()
+			l.templates = append(l.templates, 0)
+			if continued {
+				tokenType = token.TEMPLATE_MIDDLE
+			} else {
+				tokenType = token.TEMPLATE_HEAD
+			}
+			if invalid != "" {
+				return newIllegalToken("%s", invalid)
+			}
+			return token.Token{Type: tokenType, Literal: buffer.String()}
+		case '\\':
+			l.readChar()
+			if l.currentChar == 0 {
+				return newIllegalToken("unterminated string")
+			}
+			if message := l.readEscape(&buffer); message != "" && invalid == "" {
+				invalid = message
+			}
+		default:
+			buffer.WriteRune(l.currentChar)
+		}
+	}
+
+	if invalid != "" {
+		return newIllegalToken("%s", invalid)
+	}
+	return token.Token{Type: tokenType, Literal: buffer.String()}
+}
+
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'\\': '\\',
+	'"':  '"',
+	'$':  '$',
+}
+
+// readEscape decodes the escape sequence following a backslash into the
+// buffer, leaving the lexer on its last character. An invalid sequence is
+// described by the returned message.
+func (l *Lexer) readEscape(buffer *strings.Builder) string {
+	if char, ok := escapes[l.currentChar]; ok {
+		buffer.WriteRune(char)
+		return ""
+	}
+	if l.currentChar != 'u' {
+		return fmt.Sprintf("invalid escape sequence \\%c", l.currentChar)
+	}
+
+	// \u{1F600}: from 1 to 6 hexadecimal digits within braces
+	if l.peekChar() != '{' {
+		return "invalid unicode escape sequence, expected '{' after \\u"
+	}
+	l.readChar()
+	start := l.nextPosition
+	for isHexDigit(l.peekChar()) {
 		l.readChar()
 	}
+	digits := l.input[start:l.nextPosition]
+	if l.peekChar() != '}' || len(digits) < 1 || len(digits) > 6 {
+		return "invalid unicode escape sequence, expected 1 to 6 hexadecimal digits within braces"
+	}
+	l.readChar()
+	code, _ := strconv.ParseUint(digits, 16, 32)
+	if !utf8.ValidRune(rune(code)) {
+		return fmt.Sprintf("invalid unicode escape sequence, %s is not a valid code point", digits)
+	}
+	buffer.WriteRune(rune(code))
+	return ""
+}
 
-	return l.input[pos:l.currentPosition]
+// readRawString reads a string within backticks, which spans lines and has no
+// escape sequences
+func (l *Lexer) readRawString() token.Token {
+	l.readChar()
+	pos := l.currentPosition
+	for l.currentChar != '`' {
+		if l.currentChar == 0 {
+			return newIllegalToken("unterminated raw string")
+		}
+		if l.invalidChar() {
+			for l.currentChar != '`' && l.currentChar != 0 {
+				l.readChar()
+			}
+			return newIllegalToken("invalid UTF-8 encoding in string")
+		}
+		l.readChar()
+	}
+	return token.Token{Type: token.STRING, Literal: l.input[pos:l.currentPosition]}
 }
 
-func isDigit(char byte) bool {
+func isDigit(char rune) bool {
 	return char >= '0' && char <= '9'
 }
 
-func isLetter(char byte) bool {
-	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+func isHexDigit(char rune) bool {
+	return isDigit(char) || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+func isLetter(char rune) bool {
+	return unicode.IsLetter(char) || char == '_'
 }
 
-func isWhiteSpace(char byte) bool {
+func isWhiteSpace(char rune) bool {
 	if _, ok := WHITESPACES[char]; ok {
 		return true
 	}
 	return false
 }
 
-func newToken(tokenType token.TokenType, literal byte) token.Token {
+func newToken(tokenType token.TokenType, literal rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(literal)}
 }
+
+// newIllegalToken builds an ILLEGAL token whose literal explains what is wrong
+func newIllegalToken(template string, params ...interface{}) token.Token {
+	return token.Token{Type: token.ILLEGAL, Literal: fmt.Sprintf(template, params...)}
+}
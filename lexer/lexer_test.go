@@ -8,7 +8,8 @@ import (
 func TestNextToken(t *testing.T) {
 	input := `
 		let a_b = 5 + 10;
-		!/*%^
+		!/ *%^
+		+= -= *= /= %=
 		(1 + 2) * 3 == 9;
 		0 != 9
 		4 > 3 >= 3 < 2 <= 2;
@@ -36,6 +37,11 @@ func TestNextToken(t *testing.T) {
 		{token.ASTERISK, "*"},
 		{token.PERCENT, "%"},
 		{token.POWER, "^"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.MINUS_ASSIGN, "-="},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.SLASH_ASSIGN, "/="},
+		{token.PERCENT_ASSIGN, "%="},
 		{token.LPAREN, "("},
 		{token.INT, "1"},
 		{token.PLUS, "+"},
@@ -115,3 +121,342 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let a = 5;\n  \"hi\" >= x"
+	expected := []struct {
+		expectedType  token.TokenType
+		expectedStart token.Position
+		expectedEnd   token.Position
+	}{
+		{token.LET, token.Position{Filename: "pos.ngo", Line: 1, Column: 1, Offset: 0},
+			token.Position{Filename: "pos.ngo", Line: 1, Column: 4, Offset: 3}},
+		{token.IDENTIFIER, token.Position{Filename: "pos.ngo", Line: 1, Column: 5, Offset: 4},
+			token.Position{Filename: "pos.ngo", Line: 1, Column: 6, Offset: 5}},
+		{token.ASSIGNMENT, token.Position{Filename: "pos.ngo", Line: 1, Column: 7, Offset: 6},
+			token.Position{Filename: "pos.ngo", Line: 1, Column: 8, Offset: 7}},
+		{token.INT, token.Position{Filename: "pos.ngo", Line: 1, Column: 9, Offset: 8},
+			token.Position{Filename: "pos.ngo", Line: 1, Column: 10, Offset: 9}},
+		{token.SEMICOLON, token.Position{Filename: "pos.ngo", Line: 1, Column: 10, Offset: 9},
+			token.Position{Filename: "pos.ngo", Line: 1, Column: 11, Offset: 10}},
+		{token.STRING, token.Position{Filename: "pos.ngo", Line: 2, Column: 3, Offset: 13},
+			token.Position{Filename: "pos.ngo", Line: 2, Column: 7, Offset: 17}},
+		{token.GTE, token.Position{Filename: "pos.ngo", Line: 2, Column: 8, Offset: 18},
+			token.Position{Filename: "pos.ngo", Line: 2, Column: 10, Offset: 20}},
+		{token.IDENTIFIER, token.Position{Filename: "pos.ngo", Line: 2, Column: 11, Offset: 21},
+			token.Position{Filename: "pos.ngo", Line: 2, Column: 12, Offset: 22}},
+		{token.EOF, token.Position{Filename: "pos.ngo", Line: 2, Column: 12, Offset: 22},
+			token.Position{Filename: "pos.ngo", Line: 2, Column: 12, Offset: 22}},
+	}
+
+	lexer := NewWithFilename("pos.ngo", input)
+
+	for _, expectedToken := range expected {
+		actualToken := lexer.NextToken()
+		if expectedToken.expectedType != actualToken.Type {
+			t.Fatalf("Expected TokenType to be %q, got %q", expectedToken.expectedType, actualToken.Type)
+		}
+		if expectedToken.expectedStart != actualToken.Start {
+			t.Fatalf("Expected %q to start at %+v, got %+v",
+				actualToken.Literal, expectedToken.expectedStart, actualToken.Start)
+		}
+		if expectedToken.expectedEnd != actualToken.End {
+			t.Fatalf("Expected %q to end at %+v, got %+v",
+				actualToken.Literal, expectedToken.expectedEnd, actualToken.End)
+		}
+	}
+}
+
+func TestNumberTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"3.14", []token.Token{{Type: token.FLOAT, Literal: "3.14"}}},
+		{".5", []token.Token{{Type: token.FLOAT, Literal: ".5"}}},
+		{"1e-9", []token.Token{{Type: token.FLOAT, Literal: "1e-9"}}},
+		{"2.5E+3", []token.Token{{Type: token.FLOAT, Literal: "2.5E+3"}}},
+		{"10", []token.Token{{Type: token.INT, Literal: "10"}}},
+		{"1e", []token.Token{{Type: token.ILLEGAL, Literal: "invalid number literal '1e', its exponent has no digits"}}},
+		{"2.5e+", []token.Token{{Type: token.ILLEGAL, Literal: "invalid number literal '2.5e+', its exponent has no digits"}}},
+		{"1e-x", []token.Token{{Type: token.ILLEGAL, Literal: "invalid number literal '1e-', its exponent has no digits"},
+			{Type: token.IDENTIFIER, Literal: "x"}}},
+		{"1e5x", []token.Token{{Type: token.ILLEGAL, Literal: "invalid character 'x' in number literal '1e5x'"}}},
+		{"12abc3", []token.Token{{Type: token.ILLEGAL, Literal: "invalid character 'a' in number literal '12abc3'"}}},
+		{"1.5f", []token.Token{{Type: token.ILLEGAL, Literal: "invalid character 'f' in number literal '1.5f'"}}},
+		{"2-1", []token.Token{{Type: token.INT, Literal: "2"}, {Type: token.MINUS, Literal: "-"}, {Type: token.INT, Literal: "1"}}},
+		{"0xff", []token.Token{{Type: token.INT, Literal: "0xff"}}},
+		{"0XdeadBEEF", []token.Token{{Type: token.INT, Literal: "0XdeadBEEF"}}},
+		{"0o755", []token.Token{{Type: token.INT, Literal: "0o755"}}},
+		{"0b1010_1010", []token.Token{{Type: token.INT, Literal: "0b1010_1010"}}},
+		{"1_000_000", []token.Token{{Type: token.INT, Literal: "1_000_000"}}},
+		{"1_000.000_1e1_0", []token.Token{{Type: token.FLOAT, Literal: "1_000.000_1e1_0"}}},
+		{"0x", []token.Token{{Type: token.ILLEGAL, Literal: "invalid hexadecimal literal '0x', it has no digits"}}},
+		{"0b_", []token.Token{{Type: token.ILLEGAL, Literal: "invalid binary literal '0b_', it has no digits"}}},
+		{"0o78", []token.Token{{Type: token.ILLEGAL, Literal: "invalid digit '8' in octal literal '0o78'"}}},
+		{"0xfg", []token.Token{{Type: token.ILLEGAL, Literal: "invalid digit 'g' in hexadecimal literal '0xfg'"}}},
+		{"0x_1", []token.Token{{Type: token.ILLEGAL,
+			Literal: "invalid hexadecimal literal '0x_1', '_' must separate successive digits"}}},
+		{"0x1.5", []token.Token{{Type: token.ILLEGAL,
+			Literal: "invalid hexadecimal literal '0x1.5', only decimal numbers can have a fractional part"}}},
+		{"0b1.", []token.Token{{Type: token.ILLEGAL,
+			Literal: "invalid binary literal '0b1.', only decimal numbers can have a fractional part"}}},
+		{"1__0", []token.Token{{Type: token.ILLEGAL,
+			Literal: "invalid number literal '1__0', '_' must separate successive digits"}}},
+		{"1_", []token.Token{{Type: token.ILLEGAL,
+			Literal: "invalid number literal '1_', '_' must separate successive digits"}}},
+		{"1_.5", []token.Token{{Type: token.ILLEGAL,
+			Literal: "invalid number literal '1_.5', '_' must separate successive digits"}}},
+	}
+
+	for _, test := range tests {
+		lexer := New(test.input)
+		for _, expected := range test.expected {
+			actual := lexer.NextToken()
+			if actual.Type != expected.Type || actual.Literal != expected.Literal {
+				t.Errorf("%s: expected %s %q. Got %s %q",
+					test.input, expected.Type, expected.Literal, actual.Type, actual.Literal)
+			}
+		}
+		if tok := lexer.NextToken(); tok.Type != token.EOF {
+			t.Errorf("%s: expected EOF. Got %s %q", test.input, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestLogicalOperatorTokens(t *testing.T) {
+	lexer := New("a && b || c ?? d ? e")
+	expected := []token.Token{
+		{Type: token.IDENTIFIER, Literal: "a"},
+		{Type: token.AND, Literal: "&&"},
+		{Type: token.IDENTIFIER, Literal: "b"},
+		{Type: token.OR, Literal: "||"},
+		{Type: token.IDENTIFIER, Literal: "c"},
+		{Type: token.NULLISH, Literal: "??"},
+		{Type: token.IDENTIFIER, Literal: "d"},
+		{Type: token.ILLEGAL, Literal: "unexpected character '?'"},
+		{Type: token.IDENTIFIER, Literal: "e"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for _, expectedToken := range expected {
+		actual := lexer.NextToken()
+		if actual.Type != expectedToken.Type || actual.Literal != expectedToken.Literal {
+			t.Errorf("expected %s %q. Got %s %q",
+				expectedToken.Type, expectedToken.Literal, actual.Type, actual.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading
+let a = 1; // trailing
+/* block /* nested */ still block */ a / 2
+/**/`
+	expected := []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENTIFIER, Literal: "a"},
+		{Type: token.ASSIGNMENT, Literal: "="},
+		{Type: token.INT, Literal: "1"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENTIFIER, Literal: "a"},
+		{Type: token.SLASH, Literal: "/"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lexer := New(input)
+	for _, expectedToken := range expected {
+		actual := lexer.NextToken()
+		if actual.Type != expectedToken.Type || actual.Literal != expectedToken.Literal {
+			t.Fatalf("expected %s %q. Got %s %q",
+				expectedToken.Type, expectedToken.Literal, actual.Type, actual.Literal)
+		}
+	}
+
+	comments := []struct {
+		literal string
+		start   string
+		end     string
+	}{
+		{"// leading", "1:1", "1:11"},
+		{"// trailing", "2:12", "2:23"},
+		{"/* block /* nested */ still block */", "3:1", "3:37"},
+		{"/**/", "4:1", "4:5"},
+	}
+	if len(lexer.Comments()) != len(comments) {
+		t.Fatalf("expected %d comments. Got %d", len(comments), len(lexer.Comments()))
+	}
+	for index, expectedComment := range comments {
+		comment := lexer.Comments()[index]
+		if comment.Type != token.COMMENT || comment.Literal != expectedComment.literal {
+			t.Errorf("expected comment %q. Got %s %q", expectedComment.literal, comment.Type, comment.Literal)
+		}
+		if comment.Start.String() != expectedComment.start || comment.End.String() != expectedComment.end {
+			t.Errorf("expected %q at %s-%s. Got %s-%s", expectedComment.literal,
+				expectedComment.start, expectedComment.end, comment.Start, comment.End)
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	lexer := New("a /* open /* nested */\n")
+	lexer.NextToken()
+	tok := lexer.NextToken()
+	if tok.Type != token.ILLEGAL || tok.Literal != "unterminated block comment" {
+		t.Fatalf("expected an unterminated block comment. Got %s %q", tok.Type, tok.Literal)
+	}
+	if tok.Start.String() != "1:3" {
+		t.Errorf("expected the error at 1:3. Got %s", tok.Start)
+	}
+	if tok = lexer.NextToken(); tok.Type != token.EOF {
+		t.Errorf("expected EOF. Got %s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestStringTokens(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"plain"`, token.STRING, "plain"},
+		{`"a\"b"`, token.STRING, `a"b`},
+		{`"line\nnext\ttab\r"`, token.STRING, "line\nnext\ttab\r"},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"\u{1F600} \u{e9}"`, token.STRING, "\U0001F600 é"},
+		{"`raw \\n \"quoted\"\nlines`", token.STRING, "raw \\n \"quoted\"\nlines"},
+		{"``", token.STRING, ""},
+		{`"open`, token.ILLEGAL, "unterminated string"},
+		{`"open\"`, token.ILLEGAL, "unterminated string"},
+		{`"open\`, token.ILLEGAL, "unterminated string"},
+		{"`open", token.ILLEGAL, "unterminated raw string"},
+		{`"\q"`, token.ILLEGAL, `invalid escape sequence \q`},
+		{`"\u00e9"`, token.ILLEGAL, `invalid unicode escape sequence, expected '{' after \u`},
+		{`"\u{}"`, token.ILLEGAL,
+			"invalid unicode escape sequence, expected 1 to 6 hexadecimal digits within braces"},
+		{`"\u{1234567}"`, token.ILLEGAL,
+			"invalid unicode escape sequence, expected 1 to 6 hexadecimal digits within braces"},
+		{`"\u{D800}"`, token.ILLEGAL, "invalid unicode escape sequence, D800 is not a valid code point"},
+	}
+
+	for _, test := range tests {
+		tok := New(test.input).NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Errorf("%s: expected %s %q. Got %s %q",
+				test.input, test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestInvalidEscapeResumesAfterString(t *testing.T) {
+	lexer := New(`"\q" + 1`)
+	expected := []token.TokenType{token.ILLEGAL, token.PLUS, token.INT, token.EOF}
+	for _, expectedType := range expected {
+		if tok := lexer.NextToken(); tok.Type != expectedType {
+			t.Errorf("expected %s. Got %s %q", expectedType, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestTemplateTokens(t *testing.T) {
+	lexer := New(`"a ${x + {"k": 1}["k"]} b ${"in${y}"}" } "$5"`)
+	expected := []token.Token{
+		{Type: token.TEMPLATE_HEAD, Literal: "a "},
+		{Type: token.IDENTIFIER, Literal: "x"},
+		{Type: token.PLUS, Literal: "+"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.STRING, Literal: "k"},
+		{Type: token.COLON, Literal: ":"},
+		{Type: token.INT, Literal: "1"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.STRING, Literal: "k"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.TEMPLATE_MIDDLE, Literal: " b "},
+		{Type: token.TEMPLATE_HEAD, Literal: "in"},
+		{Type: token.IDENTIFIER, Literal: "y"},
+		{Type: token.TEMPLATE_TAIL, Literal: ""},
+		{Type: token.TEMPLATE_TAIL, Literal: ""},
+		// Outside of templates, braces are left alone
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.STRING, Literal: "$5"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for _, expectedToken := range expected {
+		actual := lexer.NextToken()
+		if actual.Type != expectedToken.Type || actual.Literal != expectedToken.Literal {
+			t.Fatalf("expected %s %q. Got %s %q",
+				expectedToken.Type, expectedToken.Literal, actual.Type, actual.Literal)
+		}
+	}
+}
+
+func TestUnterminatedTemplate(t *testing.T) {
+	lexer := New(`"a ${x} b`)
+	for _, expectedType := range []token.TokenType{token.TEMPLATE_HEAD, token.IDENTIFIER, token.ILLEGAL} {
+		if tok := lexer.NextToken(); tok.Type != expectedType {
+			t.Fatalf("expected %s. Got %s %q", expectedType, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeTokens(t *testing.T) {
+	lexer := New("let año = \"ñ😀\";\nπ + été\n\xff \"a\xffb\"")
+	expected := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedStart   string
+	}{
+		{token.LET, "let", "1:1"},
+		{token.IDENTIFIER, "año", "1:5"},
+		{token.ASSIGNMENT, "=", "1:9"},
+		{token.STRING, "ñ😀", "1:11"},
+		{token.SEMICOLON, ";", "1:15"},
+		{token.IDENTIFIER, "π", "2:1"},
+		{token.PLUS, "+", "2:3"},
+		{token.IDENTIFIER, "été", "2:5"},
+		{token.ILLEGAL, "invalid UTF-8 encoding", "3:1"},
+		{token.ILLEGAL, "invalid UTF-8 encoding in string", "3:3"},
+		{token.EOF, "", "3:8"},
+	}
+
+	for _, expectedToken := range expected {
+		actual := lexer.NextToken()
+		if actual.Type != expectedToken.expectedType || actual.Literal != expectedToken.expectedLiteral {
+			t.Fatalf("expected %s %q. Got %s %q",
+				expectedToken.expectedType, expectedToken.expectedLiteral, actual.Type, actual.Literal)
+		}
+		if actual.Start.String() != expectedToken.expectedStart {
+			t.Errorf("expected %q at %s. Got %s", actual.Literal, expectedToken.expectedStart, actual.Start)
+		}
+	}
+}
+
+func TestBitwiseOperatorTokens(t *testing.T) {
+	lexer := New("a & b | ~c << 2 >> 1 <= >=")
+	expected := []token.Token{
+		{Type: token.IDENTIFIER, Literal: "a"},
+		{Type: token.BIT_AND, Literal: "&"},
+		{Type: token.IDENTIFIER, Literal: "b"},
+		{Type: token.BIT_OR, Literal: "|"},
+		{Type: token.BIT_NOT, Literal: "~"},
+		{Type: token.IDENTIFIER, Literal: "c"},
+		{Type: token.SHIFT_LEFT, Literal: "<<"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.SHIFT_RIGHT, Literal: ">>"},
+		{Type: token.INT, Literal: "1"},
+		{Type: token.LTE, Literal: "<="},
+		{Type: token.GTE, Literal: ">="},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for _, expectedToken := range expected {
+		actual := lexer.NextToken()
+		if actual.Type != expectedToken.Type || actual.Literal != expectedToken.Literal {
+			t.Errorf("expected %s %q. Got %s %q",
+				expectedToken.Type, expectedToken.Literal, actual.Type, actual.Literal)
+		}
+	}
+}
@@ -1,10 +1,102 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
+	"node.go/engine"
 	"node.go/repl"
+	"node.go/runner"
 	"os"
+	"strings"
 )
 
+// EXIT_USAGE is returned when the command line cannot be understood
+const EXIT_USAGE = 64
+
+// STDIN is the script name that makes the program be read from standard input
+const STDIN = "-"
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage:\n")
+	fmt.Fprintf(out, "  %s                      start the interactive repl\n", os.Args[0])
+	fmt.Fprintf(out, "  %s file.ngo [args...]   run a script\n", os.Args[0])
+	fmt.Fprintf(out, "  %s -e 'expr' [args...]  evaluate an expression\n", os.Args[0])
+	fmt.Fprintf(out, "  %s - [args...]          run a script read from stdin\n", os.Args[0])
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// isInteractive tells whether the standard input is attached to a terminal
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func readScript(name string) (string, error) {
+	var code []byte
+	var err error
+	if name == STDIN {
+		code, err = io.ReadAll(os.Stdin)
+	} else {
+		code, err = os.ReadFile(name)
+	}
+	return string(code), err
+}
+
+// isFlagSet tells whether the flag was given on the command line, even with an
+// empty value
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func main() {
-	repl.Start(os.Stdin, os.Stdout)
+	expression := flag.String("e", "", "evaluate the given expression instead of a script")
+	engineName := flag.String("engine", engine.EVALUATOR,
+		"engine running the code: "+strings.Join(engine.Names, " or "))
+	flag.Usage = usage
+	flag.Parse()
+
+	eng, err := engine.New(*engineName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(EXIT_USAGE)
+	}
+
+	args := flag.Args()
+
+	if isFlagSet("e") {
+		os.Exit(runner.RunWithEngine(eng, "<expression>", *expression, args, os.Stdout, os.Stderr))
+	}
+
+	if len(args) < 1 {
+		if isInteractive() {
+			repl.StartWithEngine(os.Stdin, os.Stdout, eng)
+			return
+		}
+		// Piped programs: node.go < script.ngo
+		args = []string{STDIN}
+	}
+
+	name := args[0]
+	code, err := readScript(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(EXIT_USAGE)
+	}
+	if name == STDIN {
+		name = "<stdin>"
+	}
+
+	os.Exit(runner.RunWithEngine(eng, name, code, args[1:], os.Stdout, os.Stderr))
 }
@@ -0,0 +1,54 @@
+package object
+
+import (
+	"hash/fnv"
+	"math/big"
+)
+
+// bigIntegerKey sets the hash keys of big integers apart from the ones of
+// integers, whose values they can never take
+const bigIntegerKey Type = "BIG INTEGER"
+
+// BigInteger holds the integers an int64 cannot. It is an integer to programs,
+// which never see the difference: results that fit an int64 are demoted back
+// to an Integer.
+type BigInteger struct {
+	Value *big.Int
+}
+
+// NewBigInteger returns the integer holding the value, an Integer whenever the
+// value fits one
+func NewBigInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return NewInteger(value.Int64())
+	}
+	return &BigInteger{Value: value}
+}
+
+func (b *BigInteger) Type() Type {
+	return INT
+}
+
+func (b *BigInteger) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInteger) HashKey() HashKey {
+	hash := fnv.New64()
+	if b.Value.Sign() < 0 {
+		_, _ = hash.Write([]byte{'-'})
+	}
+	_, _ = hash.Write(b.Value.Bytes())
+	return HashKey{Type: bigIntegerKey, Value: hash.Sum64()}
+}
+
+// ToBigInt returns the value of an Integer or a BigInteger as a big.Int
+func ToBigInt(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInteger:
+		return obj.Value, true
+	}
+	return nil, false
+}
@@ -1,6 +1,9 @@
 package object
 
-import "fmt"
+import (
+	"fmt"
+	"unicode/utf8"
+)
 
 type BuiltinFunction func(...Object) Object
 
@@ -14,6 +17,10 @@ var builtins = map[string]*Builtin{
 		Name: "len",
 		Fn:   Len,
 	},
+	"byte_len": {
+		Name: "byte_len",
+		Fn:   ByteLen,
+	},
 	"head": {
 		Name: "head",
 		Fn:   Head,
@@ -34,6 +41,26 @@ var builtins = map[string]*Builtin{
 		Name: "pop",
 		Fn:   Pop,
 	},
+	"int": {
+		Name: "int",
+		Fn:   ToInt,
+	},
+	"float": {
+		Name: "float",
+		Fn:   ToFloat,
+	},
+	"round": {
+		Name: "round",
+		Fn:   Round,
+	},
+	"json_encode": {
+		Name: "json_encode",
+		Fn:   JSONEncode,
+	},
+	"json_decode": {
+		Name: "json_decode",
+		Fn:   JSONDecode,
+	},
 }
 
 func (b *Builtin) Type() Type {
@@ -48,6 +75,19 @@ func LookUpBuiltin(name string) (*Builtin, bool) {
 	return value, ok
 }
 
+// Builtins is a table of builtins of their own, such as the functions a Go
+// program embedding the language provides, which take precedence over the
+// builtins shared by every program
+type Builtins map[string]*Builtin
+
+// LookUp finds the builtin in the table, or among the shared builtins
+func (b Builtins) LookUp(name string) (*Builtin, bool) {
+	if builtin, ok := b[name]; ok {
+		return builtin, true
+	}
+	return LookUpBuiltin(name)
+}
+
 // LEN
 
 func Len(arguments ...Object) Object {
@@ -57,15 +97,31 @@ func Len(arguments ...Object) Object {
 	}
 	switch obj := arguments[0].(type) {
 	case *String:
-		return NewInteger(int64(len(obj.Value)))
+		return NewInteger(int64(utf8.RuneCountInString(obj.Value)))
 	case *Array:
 		return NewInteger(int64(len(obj.Items)))
 	case *Hash:
-		return NewInteger(int64(len(obj.Pairs)))
+		return NewInteger(int64(obj.Len()))
 	}
 	return NewError(fmt.Sprintf("type mismatch: Expected STRING, ARRAY or HASH. Got %s", arguments[0].Type()))
 }
 
+// BYTE_LEN
+
+// ByteLen returns the length of a string in bytes of its UTF-8 encoding, where
+// len counts code points
+func ByteLen(arguments ...Object) Object {
+	if len(arguments) != 1 {
+		return NewError(fmt.Sprintf("type error: Expected 1 argument. Got %d",
+			len(arguments)))
+	}
+	str, ok := arguments[0].(*String)
+	if !ok {
+		return NewError(fmt.Sprintf("type mismatch: Expected STRING. Got %s", arguments[0].Type()))
+	}
+	return NewInteger(int64(len(str.Value)))
+}
+
 // HEAD
 
 func Head(arguments ...Object) Object {
@@ -0,0 +1,43 @@
+package object
+
+import (
+	"node.go/ast"
+	"node.go/code"
+)
+
+// CompiledFunction is the bytecode of a function literal
+type CompiledFunction struct {
+	Name          string
+	Instructions  code.Instructions
+	Positions     code.Positions
+	NumLocals     int
+	NumParameters int
+
+	Literal *ast.FunctionLiteral // nil for the main program
+}
+
+func (cf *CompiledFunction) Type() Type {
+	return COMPILED_FUNCTION
+}
+
+func (cf *CompiledFunction) Inspect() string {
+	if cf.Literal == nil {
+		return "<program>"
+	}
+	return cf.Literal.String()
+}
+
+// Closure is a compiled function along with the free variables it has
+// captured. It is the virtual machine counterpart of Function.
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+func (c *Closure) Type() Type {
+	return FUNCTION
+}
+
+func (c *Closure) Inspect() string {
+	return c.Fn.Inspect()
+}
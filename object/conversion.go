@@ -0,0 +1,105 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// INT
+
+// ToInt converts a number or a numeric string to an integer. Floats are
+// truncated toward zero.
+func ToInt(arguments ...Object) Object {
+	if len(arguments) != 1 {
+		return NewError(fmt.Sprintf("type error: Expected 1 argument. Got %d",
+			len(arguments)))
+	}
+	switch obj := arguments[0].(type) {
+	case *Integer, *BigInteger:
+		return obj
+	case *Float:
+		return floatToInt(math.Trunc(obj.Value))
+	case *String:
+		value, ok := new(big.Int).SetString(strings.TrimSpace(obj.Value), 10)
+		if !ok {
+			return NewError(fmt.Sprintf("value error: cannot convert %q to %s", obj.Value, INT))
+		}
+		return NewBigInteger(value)
+	}
+	return NewError(fmt.Sprintf("type mismatch: Expected INTEGER, FLOAT or STRING. Got %s", arguments[0].Type()))
+}
+
+// FLOAT
+
+// ToFloat converts a number or a numeric string to a float
+func ToFloat(arguments ...Object) Object {
+	if len(arguments) != 1 {
+		return NewError(fmt.Sprintf("type error: Expected 1 argument. Got %d",
+			len(arguments)))
+	}
+	switch obj := arguments[0].(type) {
+	case *Integer:
+		return NewFloat(float64(obj.Value))
+	case *BigInteger:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return NewFloat(value)
+	case *Float:
+		return obj
+	case *String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(obj.Value), 64)
+		if err != nil {
+			return NewError(fmt.Sprintf("value error: cannot convert %q to %s", obj.Value, FLOAT))
+		}
+		return NewFloat(value)
+	}
+	return NewError(fmt.Sprintf("type mismatch: Expected INTEGER, FLOAT or STRING. Got %s", arguments[0].Type()))
+}
+
+// ROUND
+
+// Round rounds a number half away from zero. Without digits the result is an
+// integer, with digits it is a float rounded to that many decimal places.
+func Round(arguments ...Object) Object {
+	if len(arguments) != 1 && len(arguments) != 2 {
+		return NewError(fmt.Sprintf("type error: Expected 1 or 2 arguments. Got %d",
+			len(arguments)))
+	}
+	var value float64
+	switch obj := arguments[0].(type) {
+	case *Integer, *BigInteger:
+		if len(arguments) == 1 {
+			return obj
+		}
+		value = ToFloat(obj).(*Float).Value
+	case *Float:
+		value = obj.Value
+	default:
+		return NewError(fmt.Sprintf("type mismatch: Expected INTEGER or FLOAT. Got %s", arguments[0].Type()))
+	}
+	if len(arguments) == 1 {
+		return floatToInt(math.Round(value))
+	}
+	digits, ok := arguments[1].(*Integer)
+	if !ok {
+		return NewError(fmt.Sprintf("type mismatch: Expected INTEGER. Got %s", arguments[1].Type()))
+	}
+	scale := math.Pow(10, float64(digits.Value))
+	if scaled := value * scale; !math.IsInf(scaled, 0) && !math.IsInf(scale, 0) && scale != 0 {
+		return NewFloat(math.Round(scaled) / scale)
+	}
+	return NewFloat(value)
+}
+
+// floatToInt converts a float holding a whole number to an integer, failing on
+// infinities and NaN
+func floatToInt(value float64) Object {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return NewError(fmt.Sprintf("value error: cannot convert %s to %s",
+			strconv.FormatFloat(value, 'g', -1, 64), INT))
+	}
+	whole, _ := big.NewFloat(value).Int(nil)
+	return NewBigInteger(whole)
+}
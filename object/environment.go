@@ -1,13 +1,30 @@
 package object
 
+// BindingKind tells how a name has been declared
+type BindingKind int
+
+const (
+	LET_BINDING   BindingKind = iota // block scoped
+	CONST_BINDING                    // block scoped, neither assigned nor redeclared
+	VAR_BINDING                      // scoped to the enclosing function
+)
+
+type binding struct {
+	value Object
+	kind  BindingKind
+}
+
 type Environment struct {
-	store map[string]Object
+	store map[string]binding
 	outer *Environment
+
+	// Set for the environments of blocks, which var declarations go through
+	block bool
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
-		store: make(map[string]Object),
+		store: make(map[string]binding),
 		outer: nil,
 	}
 }
@@ -18,15 +35,71 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewBlockEnvironment creates the environment of a block. As most blocks
+// declare nothing, its store is only allocated on the first declaration.
+func NewBlockEnvironment(outer *Environment) *Environment {
+	return &Environment{outer: outer, block: true}
+}
+
 func (e *Environment) Get(ident string) (Object, bool) {
-	value, ok := e.store[ident]
-	if !ok && e.outer != nil {
-		value, ok = e.outer.Get(ident)
+	for env := e; env != nil; env = env.outer {
+		if binding, ok := env.store[ident]; ok {
+			return binding.value, true
+		}
 	}
-	return value, ok
+	return nil, false
 }
 
+// Set binds the identifier within this environment as let does
 func (e *Environment) Set(ident string, value Object) Object {
-	e.store[ident] = value
+	return e.Declare(ident, value, LET_BINDING)
+}
+
+// Declare binds the identifier within this environment
+func (e *Environment) Declare(ident string, value Object, kind BindingKind) Object {
+	if e.store == nil {
+		e.store = make(map[string]binding)
+	}
+	e.store[ident] = binding{value: value, kind: kind}
 	return value
 }
+
+// Declared tells how the identifier is bound within this environment, not
+// looking into the enclosing ones
+func (e *Environment) Declared(ident string) (BindingKind, bool) {
+	binding, ok := e.store[ident]
+	return binding.kind, ok
+}
+
+// Lookup tells how the nearest binding of the identifier has been declared
+func (e *Environment) Lookup(ident string) (BindingKind, bool) {
+	for env := e; env != nil; env = env.outer {
+		if binding, ok := env.store[ident]; ok {
+			return binding.kind, true
+		}
+	}
+	return LET_BINDING, false
+}
+
+// Assign updates the nearest binding of the identifier, telling whether there
+// was one to update
+func (e *Environment) Assign(ident string, value Object) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if binding, ok := env.store[ident]; ok {
+			binding.value = value
+			env.store[ident] = binding
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// FunctionScope returns the environment var declarations are bound in: the one
+// of the enclosing function, or of the program
+func (e *Environment) FunctionScope() *Environment {
+	env := e
+	for env.block && env.outer != nil {
+		env = env.outer
+	}
+	return env
+}
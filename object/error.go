@@ -1,13 +1,68 @@
 package object
 
+import (
+	"bytes"
+	"fmt"
+	"node.go/token"
+)
+
+// Frame is a function call that was still running when an error was raised
+type Frame struct {
+	Function string         // name of the called function
+	Call     token.Position // where the function has been called from
+}
+
+// ErrorKind tells apart the errors raised by the program from the ones raised
+// because it went over the limits it was run with
+type ErrorKind int
+
+const (
+	RUNTIME_ERROR       ErrorKind = iota
+	CALL_DEPTH_EXCEEDED           // too many nested calls
+	STEPS_EXCEEDED                // too many evaluation steps
+	DEADLINE_EXCEEDED             // ran past its deadline
+	CANCELED                      // its context has been canceled
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case CALL_DEPTH_EXCEEDED:
+		return "call depth exceeded"
+	case STEPS_EXCEEDED:
+		return "steps exceeded"
+	case DEADLINE_EXCEEDED:
+		return "deadline exceeded"
+	case CANCELED:
+		return "canceled"
+	default:
+		return "runtime error"
+	}
+}
+
 type Error struct {
+	Kind    ErrorKind
 	Message string
+
+	Pos   token.Position // where the error has been raised
+	Trace []Frame        // innermost call first
 }
 
 func NewError(message string) *Error {
 	return &Error{Message: message}
 }
 
+// NewLimitError creates an error telling that the program has been stopped
+// for going over one of its limits
+func NewLimitError(kind ErrorKind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// IsLimit tells whether the error has been raised by a limit rather than by
+// the program itself
+func (e *Error) IsLimit() bool {
+	return e.Kind != RUNTIME_ERROR
+}
+
 func (e *Error) Type() Type {
 	return ERROR
 }
@@ -15,3 +70,26 @@ func (e *Error) Type() Type {
 func (e *Error) Inspect() string {
 	return "ERROR: " + e.Message
 }
+
+// Traceback renders the call stack that lead to the error, most recent call
+// last, followed by the error itself
+func (e *Error) Traceback() string {
+	if !e.Pos.IsValid() && len(e.Trace) < 1 {
+		return e.Inspect()
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("Traceback (most recent call last):\n")
+
+	caller := "<program>"
+	for index := len(e.Trace) - 1; index >= 0; index-- {
+		frame := e.Trace[index]
+		out.WriteString(fmt.Sprintf("  %s, in %s\n", frame.Call, caller))
+		caller = frame.Function
+	}
+	out.WriteString(fmt.Sprintf("  %s, in %s\n", e.Pos, caller))
+	out.WriteString(e.Inspect())
+
+	return out.String()
+}
@@ -0,0 +1,28 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Float struct {
+	Value float64
+}
+
+func NewFloat(value float64) *Float {
+	return &Float{Value: value}
+}
+
+func (f *Float) Type() Type {
+	return FLOAT
+}
+
+// Inspect always shows a decimal point or an exponent, so that floats holding
+// whole numbers are told apart from integers
+func (f *Float) Inspect() string {
+	text := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.ContainsAny(text, ".eIN") {
+		return text
+	}
+	return text + ".0"
+}
@@ -6,7 +6,11 @@ import (
 	"strings"
 )
 
+// ANONYMOUS is the name of functions which have not been bound to a name
+const ANONYMOUS = "<anonymous>"
+
 type Function struct {
+	Name       string // the name the function was first bound to, if any
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
 	"strings"
 )
 
 type Hashable interface {
+	Object
 	HashKey() HashKey
 }
 
@@ -20,6 +23,16 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// HashKey of floats holding whole numbers is the one of the equal integer, as
+// 1.0 and 1 are equal
+func (f *Float) HashKey() HashKey {
+	if math.Trunc(f.Value) != f.Value || math.IsInf(f.Value, 0) {
+		return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+	}
+	whole, _ := big.NewFloat(f.Value).Int(nil)
+	return NewBigInteger(whole).(Hashable).HashKey()
+}
+
 func (b *Boolean) HashKey() HashKey {
 	var value uint64
 
@@ -43,12 +56,44 @@ type HashPair struct {
 	Value Object
 }
 
+// Hash keeps its pairs in the order their keys have first been set in, while
+// looking them up by key in constant time
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	pairs   []HashPair
+	indices map[HashKey]int // index of the pair of each key within pairs
 }
 
 func NewHash() *Hash {
-	return &Hash{Pairs: make(map[HashKey]HashPair)}
+	return &Hash{indices: make(map[HashKey]int)}
+}
+
+// Set binds the key to the value. Keys set again keep their place.
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if index, ok := h.indices[hashKey]; ok {
+		h.pairs[index] = HashPair{Key: key, Value: value}
+		return
+	}
+	h.indices[hashKey] = len(h.pairs)
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+}
+
+func (h *Hash) Get(key Hashable) (HashPair, bool) {
+	index, ok := h.indices[key.HashKey()]
+	if !ok {
+		return HashPair{}, false
+	}
+	return h.pairs[index], true
+}
+
+func (h *Hash) Len() int {
+	return len(h.pairs)
+}
+
+// Pairs returns the pairs in order. They belong to the hash and must not be
+// changed.
+func (h *Hash) Pairs() []HashPair {
+	return h.pairs
 }
 
 func (h *Hash) Type() Type {
@@ -59,7 +104,7 @@ func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	var pairsString []string
 
-	for _, pair := range h.Pairs {
+	for _, pair := range h.pairs {
 		pairsString = append(pairsString, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -0,0 +1,247 @@
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// JSON_ENCODE
+
+// JSONEncode renders a value as JSON: null, booleans, numbers, strings,
+// arrays, and hashes keyed by strings, whose pairs are written in the order
+// the hash keeps them. The optional indent is either a number of spaces or
+// the string to indent with, the output being compact without it.
+func JSONEncode(arguments ...Object) Object {
+	if len(arguments) != 1 && len(arguments) != 2 {
+		return NewError(fmt.Sprintf("type error: Expected 1 or 2 arguments. Got %d",
+			len(arguments)))
+	}
+
+	var indent string
+	if len(arguments) == 2 {
+		switch obj := arguments[1].(type) {
+		case *Integer:
+			if obj.Value < 0 || obj.Value > MAX_JSON_INDENT {
+				return NewError(fmt.Sprintf("value error: indent must be between 0 and %d. Got %d",
+					MAX_JSON_INDENT, obj.Value))
+			}
+			indent = strings.Repeat(" ", int(obj.Value))
+		case *String:
+			indent = obj.Value
+		default:
+			return NewError(fmt.Sprintf("type mismatch: Expected INTEGER or STRING. Got %s", arguments[1].Type()))
+		}
+	}
+
+	encoder := &jsonEncoder{visiting: make(map[Object]bool)}
+	if err := encoder.encode(arguments[0]); err != nil {
+		return err
+	}
+	if len(arguments) == 1 {
+		return NewString(encoder.out.String())
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, encoder.out.Bytes(), "", indent); err != nil {
+		return NewError(fmt.Sprintf("value error: %s", err))
+	}
+	return NewString(out.String())
+}
+
+// MAX_JSON_INDENT bounds the number of spaces json_encode may indent with
+const MAX_JSON_INDENT = 64
+
+type jsonEncoder struct {
+	out bytes.Buffer
+	// Arrays and hashes being encoded, to tell cycles apart
+	visiting map[Object]bool
+}
+
+func (e *jsonEncoder) encode(obj Object) *Error {
+	switch obj := obj.(type) {
+	case *Null:
+		e.out.WriteString("null")
+	case *Boolean, *Integer, *BigInteger:
+		e.out.WriteString(obj.Inspect())
+	case *Float:
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return NewError(fmt.Sprintf("value error: cannot encode %s as JSON", obj.Inspect()))
+		}
+		e.out.WriteString(obj.Inspect())
+	case *String:
+		e.encodeString(obj.Value)
+	case *Array:
+		if err := e.enter(obj); err != nil {
+			return err
+		}
+		e.out.WriteByte('[')
+		for index, item := range obj.Items {
+			if index > 0 {
+				e.out.WriteByte(',')
+			}
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+		e.out.WriteByte(']')
+		delete(e.visiting, obj)
+	case *Hash:
+		if err := e.enter(obj); err != nil {
+			return err
+		}
+		e.out.WriteByte('{')
+		for index, pair := range obj.Pairs() {
+			key, ok := pair.Key.(*String)
+			if !ok {
+				return NewError(fmt.Sprintf("type error: cannot encode hash key of type %s as JSON, keys must be strings",
+					pair.Key.Type()))
+			}
+			if index > 0 {
+				e.out.WriteByte(',')
+			}
+			e.encodeString(key.Value)
+			e.out.WriteByte(':')
+			if err := e.encode(pair.Value); err != nil {
+				return err
+			}
+		}
+		e.out.WriteByte('}')
+		delete(e.visiting, obj)
+	default:
+		return NewError(fmt.Sprintf("type error: cannot encode %s as JSON", obj.Type()))
+	}
+	return nil
+}
+
+func (e *jsonEncoder) enter(obj Object) *Error {
+	if e.visiting[obj] {
+		return NewError(fmt.Sprintf("value error: cannot encode %s as JSON, it contains itself", obj.Type()))
+	}
+	e.visiting[obj] = true
+	return nil
+}
+
+func (e *jsonEncoder) encodeString(value string) {
+	// Unlike json.Marshal, the encoder can be told to leave <, > and & as is
+	encoder := json.NewEncoder(&e.out)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	e.out.Truncate(e.out.Len() - 1) // the newline Encode ends with
+}
+
+// JSON_DECODE
+
+// JSONDecode parses JSON into the values json_encode renders: objects become
+// hashes keyed by strings, and numbers integers unless they have a fraction or
+// an exponent, in which case they become floats.
+func JSONDecode(arguments ...Object) Object {
+	if len(arguments) != 1 {
+		return NewError(fmt.Sprintf("type error: Expected 1 argument. Got %d",
+			len(arguments)))
+	}
+	str, ok := arguments[0].(*String)
+	if !ok {
+		return NewError(fmt.Sprintf("type mismatch: Expected STRING. Got %s", arguments[0].Type()))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(str.Value))
+	decoder.UseNumber()
+	value := decodeJSON(decoder)
+	if _, ok := value.(*Error); ok {
+		return value
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return NewError("value error: invalid JSON, unexpected data after the value")
+	}
+	return value
+}
+
+func decodeJSON(decoder *json.Decoder) Object {
+	token, err := decoder.Token()
+	if err != nil {
+		return invalidJSON(err)
+	}
+
+	switch token := token.(type) {
+	case nil:
+		return NULL
+	case bool:
+		if token {
+			return TRUE
+		}
+		return FALSE
+	case json.Number:
+		return decodeJSONNumber(token)
+	case string:
+		return NewString(token)
+	case json.Delim:
+		if token == '[' {
+			items := []Object{}
+			for decoder.More() {
+				item := decodeJSON(decoder)
+				if _, ok := item.(*Error); ok {
+					return item
+				}
+				items = append(items, item)
+			}
+			if err := closeJSON(decoder); err != nil {
+				return err
+			}
+			return NewArray(items)
+		}
+
+		hash := NewHash()
+		for decoder.More() {
+			key := decodeJSON(decoder)
+			if _, ok := key.(*Error); ok {
+				return key
+			}
+			value := decodeJSON(decoder)
+			if _, ok := value.(*Error); ok {
+				return value
+			}
+			hash.Set(key.(*String), value)
+		}
+		if err := closeJSON(decoder); err != nil {
+			return err
+		}
+		return hash
+	}
+	return NewError(fmt.Sprintf("value error: invalid JSON, unexpected %v", token))
+}
+
+// closeJSON reads the delimiter ending an array or an object
+func closeJSON(decoder *json.Decoder) *Error {
+	if _, err := decoder.Token(); err != nil {
+		return invalidJSON(err)
+	}
+	return nil
+}
+
+func invalidJSON(err error) *Error {
+	// The decoder tells apart input ending before and within a token
+	if err == io.EOF || err.Error() == "unexpected end of JSON input" {
+		return NewError("value error: invalid JSON, unexpected end of input")
+	}
+	return NewError(fmt.Sprintf("value error: invalid JSON, %s", err))
+}
+
+func decodeJSONNumber(number json.Number) Object {
+	text := number.String()
+	if !strings.ContainsAny(text, ".eE") {
+		if value, ok := new(big.Int).SetString(text, 10); ok {
+			return NewBigInteger(value)
+		}
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return NewError(fmt.Sprintf("value error: cannot decode %s, it is out of range of FLOAT", text))
+	}
+	return NewFloat(value)
+}
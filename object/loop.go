@@ -0,0 +1,28 @@
+package object
+
+// Break is the signal a break statement sends to the enclosing loop
+type Break struct{}
+
+func (b *Break) Type() Type {
+	return BREAK_TYPE
+}
+
+func (b *Break) Inspect() string {
+	return "break"
+}
+
+// Continue is the signal a continue statement sends to the enclosing loop
+type Continue struct{}
+
+func (c *Continue) Type() Type {
+	return CONTINUE_TYPE
+}
+
+func (c *Continue) Inspect() string {
+	return "continue"
+}
+
+var (
+	BREAK    = &Break{}
+	CONTINUE = &Continue{}
+)
@@ -0,0 +1,518 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TAG is the struct tag naming the hash key a field is converted to and from.
+// Fields tagged "-" are left out.
+const TAG = "ngo"
+
+var (
+	objectType = reflect.TypeOf((*Object)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+)
+
+// FromGo converts a Go value to an object:
+//
+//	nil, nil pointers         null
+//	bool                      BOOLEAN
+//	integers, *big.Int        INTEGER
+//	floats                    FLOAT
+//	string                    STRING
+//	slices and arrays         ARRAY
+//	maps                      HASH, sorted by key
+//	structs                   HASH, keyed by the field names or their tags
+//	functions                 BUILTIN FUNCTION, as wrapped by WrapFunc
+//
+// Pointers stand for the values they point to and objects for themselves.
+// Values that cannot be converted, such as channels, or that contain
+// themselves, give an *Error.
+func FromGo(value interface{}) Object {
+	if value == nil {
+		return NULL
+	}
+	if obj, ok := value.(Object); ok {
+		return obj
+	}
+	return fromGo(reflect.ValueOf(value), make(map[visit]bool))
+}
+
+// visit identifies a pointer, map or slice being converted, slices of the same
+// array being told apart by their length
+type visit struct {
+	pointer uintptr
+	length  int
+	typ     reflect.Type
+}
+
+// fromGo converts the value, visiting holding the pointers, maps and slices it
+// is found in so as to tell cycles apart
+func fromGo(value reflect.Value, visiting map[visit]bool) Object {
+	if value.Type() == bigIntType {
+		if value.IsNil() {
+			return NULL
+		}
+		return NewBigInteger(new(big.Int).Set(value.Interface().(*big.Int)))
+	}
+	if value.Type().Implements(objectType) && value.CanInterface() {
+		if kind := value.Kind(); (kind == reflect.Interface || kind == reflect.Ptr) && value.IsNil() {
+			return NULL
+		}
+		return value.Interface().(Object)
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if !value.IsNil() {
+			key := visit{pointer: value.Pointer(), typ: value.Type()}
+			if value.Kind() == reflect.Slice {
+				key.length = value.Len()
+			}
+			if visiting[key] {
+				return NewError(fmt.Sprintf("value error: cannot convert Go value of type %s, it contains itself",
+					value.Type()))
+			}
+			visiting[key] = true
+			defer delete(visiting, key)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return TRUE
+		}
+		return FALSE
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewInteger(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewBigInteger(new(big.Int).SetUint64(value.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return NewFloat(value.Float())
+	case reflect.String:
+		return NewString(value.String())
+	case reflect.Slice, reflect.Array:
+		items := make([]Object, value.Len())
+		for index := range items {
+			item := fromGo(value.Index(index), visiting)
+			if err, ok := item.(*Error); ok {
+				return err
+			}
+			items[index] = item
+		}
+		return NewArray(items)
+	case reflect.Map:
+		// Go maps have no order, the pairs are sorted by key so that the hash
+		// always comes out the same
+		pairs := make([]HashPair, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			key := fromGo(iter.Key(), visiting)
+			if err, ok := key.(*Error); ok {
+				return err
+			}
+			if _, ok := key.(Hashable); !ok {
+				return NewError(fmt.Sprintf("value error: unhashable type as hash key: %s", key.Type()))
+			}
+			item := fromGo(iter.Value(), visiting)
+			if err, ok := item.(*Error); ok {
+				return err
+			}
+			pairs = append(pairs, HashPair{Key: key, Value: item})
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return lessKey(pairs[i].Key, pairs[j].Key)
+		})
+		hash := NewHash()
+		for _, pair := range pairs {
+			hash.Set(pair.Key.(Hashable), pair.Value)
+		}
+		return hash
+	case reflect.Struct:
+		hash := NewHash()
+		for _, field := range structFields(value.Type()) {
+			item := fromGo(value.Field(field.index), visiting)
+			if err, ok := item.(*Error); ok {
+				return err
+			}
+			key := NewString(field.name)
+			hash.Set(key, item)
+		}
+		return hash
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return NULL
+		}
+		return fromGo(value.Elem(), visiting)
+	case reflect.Func:
+		if value.IsNil() {
+			return NULL
+		}
+		builtin, err := WrapFunc(ANONYMOUS, value.Interface())
+		if err != nil {
+			return NewError(err.Error())
+		}
+		return builtin
+	}
+	return NewError(fmt.Sprintf("type error: cannot convert Go value of type %s", value.Type()))
+}
+
+// lessKey orders the keys of Go maps: numbers by value, then strings, then
+// the other keys by their display form
+func lessKey(left Object, right Object) bool {
+	leftNumber, leftIsNumber := keyNumber(left)
+	rightNumber, rightIsNumber := keyNumber(right)
+	if leftIsNumber || rightIsNumber {
+		return !rightIsNumber || (leftIsNumber && leftNumber.Cmp(rightNumber) < 0)
+	}
+
+	leftString, leftIsString := left.(*String)
+	rightString, rightIsString := right.(*String)
+	if leftIsString || rightIsString {
+		return !rightIsString || (leftIsString && leftString.Value < rightString.Value)
+	}
+	return left.Inspect() < right.Inspect()
+}
+
+func keyNumber(obj Object) (*big.Float, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return new(big.Float).SetInt64(obj.Value), true
+	case *BigInteger:
+		return new(big.Float).SetInt(obj.Value), true
+	case *Float:
+		if !math.IsNaN(obj.Value) {
+			return big.NewFloat(obj.Value), true
+		}
+	}
+	return nil, false
+}
+
+// ToGo converts the object to the Go value target points to, the reverse of
+// FromGo. Objects are converted to interface{} values as the bool, int64,
+// *big.Int, float64, string, []interface{} and map[string]interface{} they
+// hold, maps being keyed by interface{} when not all of the keys are strings.
+// Targets which objects may be assigned to, such as Object, are given the
+// object itself.
+func ToGo(obj Object, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("type error: cannot convert to %T, a non-nil pointer is needed", target)
+	}
+	if err := toGo(obj, value.Elem(), ""); err != nil {
+		return errors.New(err.Message)
+	}
+	return nil
+}
+
+// toGo sets the value to the object, naming where the object comes from in
+// errors with path
+func toGo(obj Object, value reflect.Value, path string) *Error {
+	if obj == nil {
+		return NewError(fmt.Sprintf("type error: cannot convert nil%s, an object is needed", where(path)))
+	}
+	kind := value.Kind()
+	objType := reflect.TypeOf(obj)
+	if kind != reflect.Interface || value.NumMethod() > 0 {
+		if objType.AssignableTo(value.Type()) {
+			value.Set(reflect.ValueOf(obj))
+			return nil
+		}
+	}
+	if value.Type() == bigIntType {
+		if obj == NULL {
+			value.Set(reflect.Zero(bigIntType))
+			return nil
+		}
+		integer, ok := ToBigInt(obj)
+		if !ok {
+			return mismatch(INT, obj, path)
+		}
+		value.Set(reflect.ValueOf(new(big.Int).Set(integer)))
+		return nil
+	}
+
+	switch kind {
+	case reflect.Bool:
+		boolean, ok := obj.(*Boolean)
+		if !ok {
+			return mismatch(BOOL, obj, path)
+		}
+		value.SetBool(boolean.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := ToBigInt(obj)
+		if !ok {
+			return mismatch(INT, obj, path)
+		}
+		if !integer.IsInt64() || value.OverflowInt(integer.Int64()) {
+			return outOfRange(integer.String(), value, path)
+		}
+		value.SetInt(integer.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, ok := ToBigInt(obj)
+		if !ok {
+			return mismatch(INT, obj, path)
+		}
+		if !integer.IsUint64() || value.OverflowUint(integer.Uint64()) {
+			return outOfRange(integer.String(), value, path)
+		}
+		value.SetUint(integer.Uint64())
+	case reflect.Float32, reflect.Float64:
+		var float float64
+		switch obj := obj.(type) {
+		case *Float:
+			float = obj.Value
+		case *Integer, *BigInteger:
+			integer, _ := ToBigInt(obj)
+			float, _ = new(big.Float).SetInt(integer).Float64()
+		default:
+			return mismatch(FLOAT, obj, path)
+		}
+		if value.OverflowFloat(float) {
+			return outOfRange(obj.Inspect(), value, path)
+		}
+		value.SetFloat(float)
+	case reflect.String:
+		str, ok := obj.(*String)
+		if !ok {
+			return mismatch(STRING, obj, path)
+		}
+		value.SetString(str.Value)
+	case reflect.Slice, reflect.Array:
+		array, ok := obj.(*Array)
+		if !ok {
+			return mismatch(ARRAY, obj, path)
+		}
+		if kind == reflect.Slice {
+			value.Set(reflect.MakeSlice(value.Type(), len(array.Items), len(array.Items)))
+		} else if value.Len() != len(array.Items) {
+			return NewError(fmt.Sprintf("value error: Expected %d items%s. Got %d",
+				value.Len(), where(path), len(array.Items)))
+		}
+		for index, item := range array.Items {
+			if err := toGo(item, value.Index(index), fmt.Sprintf("%s[%d]", path, index)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch(HASH, obj, path)
+		}
+		value.Set(reflect.MakeMapWithSize(value.Type(), hash.Len()))
+		for _, pair := range hash.Pairs() {
+			key := reflect.New(value.Type().Key()).Elem()
+			if err := toGo(pair.Key, key, path); err != nil {
+				return err
+			}
+			item := reflect.New(value.Type().Elem()).Elem()
+			if err := toGo(pair.Value, item, fmt.Sprintf("%s[%s]", path, pair.Key.Inspect())); err != nil {
+				return err
+			}
+			value.SetMapIndex(key, item)
+		}
+	case reflect.Struct:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch(HASH, obj, path)
+		}
+		// Keys without a field are left out, as are fields without a key
+		for _, field := range structFields(value.Type()) {
+			key := NewString(field.name)
+			pair, ok := hash.Get(key)
+			if !ok {
+				continue
+			}
+			if err := toGo(pair.Value, value.Field(field.index), fmt.Sprintf("%s[%s]", path, key.Inspect())); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if obj == NULL {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		pointer := reflect.New(value.Type().Elem())
+		if err := toGo(obj, pointer.Elem(), path); err != nil {
+			return err
+		}
+		value.Set(pointer)
+	case reflect.Interface:
+		if value.NumMethod() > 0 {
+			return NewError(fmt.Sprintf("type error: cannot convert %s%s to Go type %s", obj.Type(), where(path), value.Type()))
+		}
+		native := toNative(obj)
+		if native == nil {
+			value.Set(reflect.Zero(value.Type()))
+		} else {
+			value.Set(reflect.ValueOf(native))
+		}
+	default:
+		return NewError(fmt.Sprintf("type error: cannot convert %s%s to Go type %s", obj.Type(), where(path), value.Type()))
+	}
+	return nil
+}
+
+// toNative returns the Go value an object stands for when nothing tells which
+// Go type it should be converted to
+func toNative(obj Object) interface{} {
+	switch obj := obj.(type) {
+	case *Null:
+		return nil
+	case *Boolean:
+		return obj.Value
+	case *Integer:
+		return obj.Value
+	case *BigInteger:
+		return new(big.Int).Set(obj.Value)
+	case *Float:
+		return obj.Value
+	case *String:
+		return obj.Value
+	case *Array:
+		items := make([]interface{}, len(obj.Items))
+		for index, item := range obj.Items {
+			items[index] = toNative(item)
+		}
+		return items
+	case *Hash:
+		byString := make(map[string]interface{}, obj.Len())
+		for _, pair := range obj.Pairs() {
+			key, ok := pair.Key.(*String)
+			if !ok {
+				break
+			}
+			byString[key.Value] = toNative(pair.Value)
+		}
+		if len(byString) == obj.Len() {
+			return byString
+		}
+		byKey := make(map[interface{}]interface{}, obj.Len())
+		for _, pair := range obj.Pairs() {
+			byKey[toNative(pair.Key)] = toNative(pair.Value)
+		}
+		return byKey
+	}
+	return obj
+}
+
+func where(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " in " + path
+}
+
+func mismatch(expected Type, obj Object, path string) *Error {
+	return NewError(fmt.Sprintf("type mismatch: Expected %s%s. Got %s", expected, where(path), obj.Type()))
+}
+
+func outOfRange(number string, value reflect.Value, path string) *Error {
+	return NewError(fmt.Sprintf("value error: %s%s is out of range for %s", number, where(path), value.Type()))
+}
+
+type structField struct {
+	index int
+	name  string
+}
+
+// structFields lists the exported fields of the struct along with the keys
+// they are converted to
+func structFields(structType reflect.Type) []structField {
+	var fields []structField
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup(TAG); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag = strings.Split(tag, ",")[0]; tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, structField{index: index, name: name})
+	}
+	return fields
+}
+
+// WrapFunc turns a Go function into a builtin. Its arguments are converted to
+// the types of the parameters as ToGo does, and its result as FromGo does.
+// The function may return nothing, a value, an error, or a value and an
+// error, a non-nil error being raised as an *Error.
+func WrapFunc(name string, fn interface{}) (*Builtin, error) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return nil, fmt.Errorf("type error: cannot wrap %T, a function is needed", fn)
+	}
+	fnType := value.Type()
+	results := fnType.NumOut()
+	failing := results > 0 && fnType.Out(results-1) == errorType
+	if results > 2 || (results == 2 && !failing) {
+		return nil, fmt.Errorf("type error: cannot wrap %s, it should return at most a value and an error", fnType)
+	}
+
+	call := func(arguments ...Object) Object {
+		if err := checkArity(fnType, len(arguments)); err != nil {
+			return err
+		}
+		values := make([]reflect.Value, len(arguments))
+		for index, argument := range arguments {
+			var parameter reflect.Type
+			if last := fnType.NumIn() - 1; fnType.IsVariadic() && index >= last {
+				parameter = fnType.In(last).Elem()
+			} else {
+				parameter = fnType.In(index)
+			}
+			values[index] = reflect.New(parameter).Elem()
+			if err := toGo(argument, values[index], fmt.Sprintf("argument %d", index+1)); err != nil {
+				return err
+			}
+		}
+
+		returned := value.Call(values)
+		if failing {
+			if err := returned[len(returned)-1]; !err.IsNil() {
+				return NewError(err.Interface().(error).Error())
+			}
+			returned = returned[:len(returned)-1]
+		}
+		if len(returned) == 0 {
+			return NULL
+		}
+		return fromGo(returned[0], make(map[visit]bool))
+	}
+	return &Builtin{Name: name, Fn: call}, nil
+}
+
+func checkArity(fnType reflect.Type, arguments int) *Error {
+	parameters := fnType.NumIn()
+	if fnType.IsVariadic() {
+		if arguments < parameters-1 {
+			return NewError(fmt.Sprintf("type error: Expected at least %s. Got %d", countArguments(parameters-1), arguments))
+		}
+		return nil
+	}
+	if arguments != parameters {
+		return NewError(fmt.Sprintf("type error: Expected %s. Got %d", countArguments(parameters), arguments))
+	}
+	return nil
+}
+
+func countArguments(count int) string {
+	if count == 1 {
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", count)
+}
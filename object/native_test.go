@@ -0,0 +1,244 @@
+package object
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X      int
+	Y      int    `ngo:"y"`
+	Label  string `ngo:"label,omitempty"`
+	Hidden bool   `ngo:"-"`
+	secret int
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+func TestFromGo(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	var nilPointer *point
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{nilPointer, "null"},
+		{true, "true"},
+		{int8(-3), "-3"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{huge, "123456789012345678901234567890"},
+		{2.5, "2.5"},
+		{"text", "'text'"},
+		{[]int{1, 2}, "[1, 2]"},
+		{[2][]string{{"a"}, nil}, "[['a'], []]"},
+		{map[string]int{"a": 1}, "{'a': 1}"},
+		{map[int]bool{2: false}, "{2: false}"},
+		{map[string]int{"b": 2, "c": 3, "a": 1}, "{'a': 1, 'b': 2, 'c': 3}"},
+		{map[int]string{9: "a", 10: "b", 1: "c"}, "{1: 'c', 9: 'a', 10: 'b'}"},
+		{map[float64]int{10: 1, -1.5: 2, 2: 3}, "{-1.5: 2, 2.0: 3, 10.0: 1}"},
+		{map[uint64]int{1 << 63: 1, 20: 2}, "{20: 2, 9223372036854775808: 1}"},
+		{map[interface{}]int{"b": 1, 10: 2, true: 3, "a": 4, 9: 5}, "{9: 5, 10: 2, 'a': 4, 'b': 1, true: 3}"},
+		{point{X: 1, Y: 2, Hidden: true, secret: 3}, "{'X': 1, 'y': 2, 'label': ''}"},
+		{&point{}, "{'X': 0, 'y': 0, 'label': ''}"},
+		{[]Object{NewString("kept"), NULL}, "['kept', null]"},
+		{NewArray(nil), "[]"},
+		{make(chan int), "ERROR: type error: cannot convert Go value of type chan int"},
+		{map[[1]int]int{{1}: 1}, "ERROR: value error: unhashable type as hash key: ARRAY"},
+	}
+
+	for _, test := range tests {
+		if obj := FromGo(test.value); obj.Inspect() != test.expected {
+			t.Errorf("FromGo(%#v): expected %s. Got %s", test.value, test.expected, obj.Inspect())
+		}
+	}
+
+	if FromGo(true) != TRUE || FromGo(false) != FALSE {
+		t.Errorf("booleans expected to be converted to TRUE and FALSE")
+	}
+}
+
+func TestFromGoCycles(t *testing.T) {
+	loop := node{Value: 1}
+	loop.Next = &loop
+	list := []interface{}{1, nil}
+	list[1] = list
+	table := map[string]interface{}{"a": 1}
+	table["self"] = table
+	shared := &point{X: 1}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"struct", loop, "ERROR: value error: cannot convert Go value of type *object.node, it contains itself"},
+		{"slice", list, "ERROR: value error: cannot convert Go value of type []interface {}, it contains itself"},
+		{"map", table, "ERROR: value error: cannot convert Go value of type map[string]interface {}, it contains itself"},
+		// The same value twice is not a cycle
+		{"shared", []*point{shared, shared}, "[{'X': 1, 'y': 0, 'label': ''}, {'X': 1, 'y': 0, 'label': ''}]"},
+		{"list", node{Value: 1, Next: &node{Value: 2}}, "{'Value': 1, 'Next': {'Value': 2, 'Next': null}}"},
+	}
+
+	for _, test := range tests {
+		if obj := FromGo(test.value); obj.Inspect() != test.expected {
+			t.Errorf("%s: expected %s. Got %s", test.name, test.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestToGo(t *testing.T) {
+	var integer int
+	var small uint8
+	var float float64
+	var text string
+	var numbers []int
+	var pair [2]string
+	var counts map[string]int
+	var p point
+	var pp *point
+	var native interface{}
+	var obj Object
+	var array *Array
+	var huge *big.Int
+
+	hash := NewHash()
+	for key, value := range map[string]Object{"X": NewInteger(1), "y": NewInteger(2), "Hidden": TRUE, "extra": NULL} {
+		hash.Set(NewString(key), value)
+	}
+	numberArray := NewArray([]Object{NewInteger(1), NewInteger(2)})
+	bigValue, _ := new(big.Int).SetString("99999999999999999999", 10)
+
+	tests := []struct {
+		obj      Object
+		target   interface{}
+		expected interface{}
+	}{
+		{NewInteger(-4), &integer, -4},
+		{NewInteger(255), &small, uint8(255)},
+		{NewInteger(3), &float, 3.0},
+		{NewFloat(0.5), &float, 0.5},
+		{NewString("hi"), &text, "hi"},
+		{numberArray, &numbers, []int{1, 2}},
+		{NewArray([]Object{NewString("a"), NewString("b")}), &pair, [2]string{"a", "b"}},
+		{hash, &p, point{X: 1, Y: 2}},
+		{hash, &pp, &point{X: 1, Y: 2}},
+		{NULL, &pp, (*point)(nil)},
+		{numberArray, &native, []interface{}{int64(1), int64(2)}},
+		{hash, &native, map[string]interface{}{"X": int64(1), "y": int64(2), "Hidden": true, "extra": nil}},
+		{numberArray, &obj, Object(numberArray)},
+		{numberArray, &array, numberArray},
+		{NewBigInteger(bigValue), &huge, bigValue},
+	}
+
+	for _, test := range tests {
+		if err := ToGo(test.obj, test.target); err != nil {
+			t.Errorf("ToGo(%s): unexpected error: %s", test.obj.Inspect(), err)
+			continue
+		}
+		got := reflect.ValueOf(test.target).Elem().Interface()
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("ToGo(%s): expected %#v. Got %#v", test.obj.Inspect(), test.expected, got)
+		}
+	}
+
+	counts = map[string]int{"stale": 1}
+	one := NewHash()
+	one.Set(NewString("a"), NewInteger(1))
+	if err := ToGo(one, &counts); err != nil || !reflect.DeepEqual(counts, map[string]int{"a": 1}) {
+		t.Errorf("expected counts to be replaced. Got %v, %v", counts, err)
+	}
+}
+
+func TestToGoErrors(t *testing.T) {
+	var small uint8
+	var numbers []int
+	var pair [2]int
+	var p point
+	var stringer interface{ String() string }
+
+	hash := NewHash()
+	hash.Set(NewString("y"), NewString("2"))
+
+	tests := []struct {
+		obj      Object
+		target   interface{}
+		expected string
+	}{
+		{NewString("1"), &small, "type mismatch: Expected INTEGER. Got STRING"},
+		{NewInteger(-1), &small, "value error: -1 is out of range for uint8"},
+		{NewArray([]Object{NewInteger(1), TRUE}), &numbers, "type mismatch: Expected INTEGER in [1]. Got BOOLEAN"},
+		{NewArray([]Object{NewInteger(1)}), &pair, "value error: Expected 2 items. Got 1"},
+		{hash, &p, "type mismatch: Expected INTEGER in ['y']. Got STRING"},
+		{NULL, &stringer, "type error: cannot convert NULL to Go type interface { String() string }"},
+		{NULL, small, "type error: cannot convert to uint8, a non-nil pointer is needed"},
+		{nil, &small, "type error: cannot convert nil, an object is needed"},
+		{NewArray([]Object{NewInteger(1), nil}), &numbers, "type error: cannot convert nil in [1], an object is needed"},
+	}
+
+	for _, test := range tests {
+		err := ToGo(test.obj, test.target)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ToGo(%s): expected error %q. Got %v", test.obj.Inspect(), test.expected, err)
+		}
+	}
+}
+
+func TestWrapFunc(t *testing.T) {
+	add, err := WrapFunc("add", func(a int, b float64) float64 { return float64(a) + b })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	join, _ := WrapFunc("join", func(separator string, parts ...string) string {
+		return strings.Join(parts, separator)
+	})
+	divide, _ := WrapFunc("divide", func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errors.New("value error: division by zero")
+		}
+		return a / b, nil
+	})
+	nothing, _ := WrapFunc("nothing", func() {})
+	named, _ := WrapFunc("named", func(p point) []string { return []string{p.Label} })
+
+	tests := []struct {
+		builtin   *Builtin
+		arguments []Object
+		expected  string
+	}{
+		{add, []Object{NewInteger(1), NewFloat(0.5)}, "1.5"},
+		{add, []Object{NewInteger(1)}, "ERROR: type error: Expected 2 arguments. Got 1"},
+		{add, []Object{NewString("1"), NewInteger(2)}, "ERROR: type mismatch: Expected INTEGER in argument 1. Got STRING"},
+		{join, []Object{NewString("-")}, "''"},
+		{join, []Object{NewString("-"), NewString("a"), NewString("b")}, "'a-b'"},
+		{join, []Object{NewString("-"), NewString("a"), NULL}, "ERROR: type mismatch: Expected STRING in argument 3. Got NULL"},
+		{join, nil, "ERROR: type error: Expected at least 1 argument. Got 0"},
+		{divide, []Object{NewInteger(7), NewInteger(2)}, "3"},
+		{divide, []Object{NewInteger(7), NewInteger(0)}, "ERROR: value error: division by zero"},
+		{nothing, nil, "null"},
+		{named, []Object{FromGo(point{Label: "p"})}, "['p']"},
+	}
+
+	for _, test := range tests {
+		if result := test.builtin.Fn(test.arguments...); result.Inspect() != test.expected {
+			t.Errorf("%s: expected %s. Got %s", test.builtin.Name, test.expected, result.Inspect())
+		}
+	}
+
+	if _, err := WrapFunc("bad", func() (int, int) { return 0, 0 }); err == nil {
+		t.Errorf("expected a function returning two values to be refused")
+	}
+	if _, err := WrapFunc("bad", 42); err == nil {
+		t.Errorf("expected a non-function to be refused")
+	}
+	if builtin := FromGo(strings.ToUpper).(*Builtin); builtin.Fn(NewString("a")).Inspect() != "'A'" {
+		t.Errorf("expected functions to be wrapped")
+	}
+}
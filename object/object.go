@@ -7,15 +7,28 @@ type Object interface {
 	Inspect() string
 }
 
+// Display returns the text of the object as shown within other strings, such
+// as templates: the value of strings, without quotes, and Inspect otherwise
+func Display(obj Object) string {
+	if str, ok := obj.(*String); ok {
+		return str.Value
+	}
+	return obj.Inspect()
+}
+
 const (
-	INT       Type = "INTEGER"
-	BOOL           = "BOOLEAN"
-	STRING         = "STRING"
-	RETURN         = "RETURN"
-	NULL_TYPE      = "NULL"
-	ERROR          = "ERROR"
-	FUNCTION       = "FUNCTION"
-	BFUNCTION      = "BUILTIN FUNCTION"
-	ARRAY          = "ARRAY"
-	HASH           = "HASH"
+	INT               Type = "INTEGER"
+	FLOAT                  = "FLOAT"
+	BOOL                   = "BOOLEAN"
+	STRING                 = "STRING"
+	RETURN                 = "RETURN"
+	NULL_TYPE              = "NULL"
+	ERROR                  = "ERROR"
+	FUNCTION               = "FUNCTION"
+	BFUNCTION              = "BUILTIN FUNCTION"
+	ARRAY                  = "ARRAY"
+	HASH                   = "HASH"
+	COMPILED_FUNCTION      = "COMPILED FUNCTION"
+	BREAK_TYPE             = "BREAK"
+	CONTINUE_TYPE          = "CONTINUE"
 )
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"node.go/token"
+	"strconv"
+	"strings"
+)
+
+type Severity int
+
+const (
+	ERROR Severity = iota
+	WARNING
+)
+
+func (s Severity) String() string {
+	switch s {
+	case WARNING:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic codes
+const (
+	UNEXPECTED_TOKEN    = "E0001" // a token other than the expected one was found
+	EXPECTED_EXPRESSION = "E0002" // the token cannot start an expression
+	INVALID_LITERAL     = "E0003" // the literal is well formed but cannot be represented
+	ILLEGAL_TOKEN       = "E0004" // the lexer could not make sense of the input
+	MISPLACED_STATEMENT = "E0005" // the statement is not allowed where it appears
+	INVALID_ASSIGNMENT  = "E0006" // the left hand side of an assignment cannot be assigned to
+)
+
+// Diagnostic describes a problem found in the source code, along with the
+// line it has been found at so that it can be reported on its own
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Start    token.Position
+	End      token.Position
+	Message  string
+	Source   string // the whole line where the diagnostic starts
+}
+
+// Error renders the diagnostic in a single line
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s", d.Start, d.Message)
+}
+
+// String renders the diagnostic along with its source line, underlining the
+// offending code:
+//
+//	error[E0001]: expected ')' but found ';'
+//	 --> script.ngo:1:15
+//	  |
+//	1 | let x = (1 + 2;
+//	  |               ^
+func (d Diagnostic) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(fmt.Sprintf("%s[%s]: %s\n", d.Severity, d.Code, d.Message))
+
+	if !d.Start.IsValid() {
+		return out.String()
+	}
+
+	lineNumber := strconv.Itoa(d.Start.Line)
+	gutter := strings.Repeat(" ", len(lineNumber))
+
+	out.WriteString(fmt.Sprintf("%s--> %s\n", gutter, d.Start))
+	out.WriteString(fmt.Sprintf("%s |\n", gutter))
+	out.WriteString(fmt.Sprintf("%s | %s\n", lineNumber, d.Source))
+	out.WriteString(fmt.Sprintf("%s | %s%s\n", gutter, d.padding(), d.underline()))
+
+	return out.String()
+}
+
+// padding reproduces the whitespace preceding the caret, keeping tabs so that
+// the underline stays aligned with the source line. Columns count characters
+// rather than bytes.
+func (d Diagnostic) padding() string {
+	var out bytes.Buffer
+	column := 1
+	for _, char := range d.Source {
+		if column >= d.Start.Column {
+			break
+		}
+		column++
+		if char == '\t' {
+			out.WriteRune('\t')
+		} else {
+			out.WriteRune(' ')
+		}
+	}
+	return out.String()
+}
+
+func (d Diagnostic) underline() string {
+	width := 1
+	if d.End.Line == d.Start.Line && d.End.Column > d.Start.Column {
+		width = d.End.Column - d.Start.Column
+	}
+	return strings.Repeat("^", width)
+}
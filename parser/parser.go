@@ -1,19 +1,29 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"node.go/ast"
 	"node.go/lexer"
 	"node.go/token"
 	"strconv"
+	"strings"
 )
 
 // Precedence priorities
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // =, +=, -=, *=, /=, %=
+	NULLISH     // ??
+	OR          // ||
+	AND         // &&
+	BIT_OR      // |
+	BIT_AND     // &
 	EQUALS      // ==
 	LESSGREATER // >, <, <=, >=
+	SHIFT       // << and >>
 	SUM         // + and -
 	MODULE      // %
 	PRODUCT     // * and /
@@ -38,6 +48,22 @@ var precedences = map[token.TokenType]int{
 	token.POWER:    POWER,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+
+	token.ASSIGNMENT:      ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.PERCENT_ASSIGN:  ASSIGN,
+
+	token.NULLISH: NULLISH,
+	token.OR:      OR,
+	token.AND:     AND,
+
+	token.BIT_OR:      BIT_OR,
+	token.BIT_AND:     BIT_AND,
+	token.SHIFT_LEFT:  SHIFT,
+	token.SHIFT_RIGHT: SHIFT,
 }
 
 func getPrecedence(tokenType token.TokenType) int {
@@ -57,7 +83,14 @@ type (
 type Parser struct {
 	lexer *lexer.Lexer
 
-	errors []string
+	diagnostics []Diagnostic
+
+	// Set after the first error of a statement so that follow-up errors caused
+	// by the parser being out of sync are not reported
+	panicking bool
+
+	// Number of loops enclosing the current statement within its function
+	loopDepth int
 
 	currentToken token.Token
 	peekToken    token.Token
@@ -68,8 +101,8 @@ type Parser struct {
 
 func New(lexer *lexer.Lexer) *Parser {
 	parser := &Parser{
-		lexer:  lexer,
-		errors: []string{},
+		lexer:       lexer,
+		diagnostics: []Diagnostic{},
 	}
 	// Read to token so as to have initialised both currentToken and peekToken
 	parser.nextToken()
@@ -83,14 +116,18 @@ func New(lexer *lexer.Lexer) *Parser {
 	parser.registerPrefixFunction(token.TRUE, parser.parseBooleanLiteral)
 	parser.registerPrefixFunction(token.FALSE, parser.parseBooleanLiteral)
 	parser.registerPrefixFunction(token.INT, parser.parseIntegerLiteral)
+	parser.registerPrefixFunction(token.FLOAT, parser.parseFloatLiteral)
 	parser.registerPrefixFunction(token.STRING, parser.parseStringLiteral)
+	parser.registerPrefixFunction(token.TEMPLATE_HEAD, parser.parseTemplateLiteral)
 	parser.registerPrefixFunction(token.BANG, parser.parsePrefixExpression)
 	parser.registerPrefixFunction(token.MINUS, parser.parsePrefixExpression)
+	parser.registerPrefixFunction(token.BIT_NOT, parser.parsePrefixExpression)
 	parser.registerPrefixFunction(token.LPAREN, parser.parseGroupedExpression)
 	parser.registerPrefixFunction(token.LBRACKET, parser.parseArrayLiteral)
 	parser.registerPrefixFunction(token.IF, parser.parseIfExpression)
 	parser.registerPrefixFunction(token.FUNC, parser.parseFunctionExpression)
 	parser.registerPrefixFunction(token.LBRACE, parser.parseHashLiteralExpression)
+	parser.registerPrefixFunction(token.ILLEGAL, parser.parseIllegalToken)
 
 	// Infix parsers
 	parser.registerInfixFunction(token.PLUS, parser.parseInfixExpression)
@@ -108,12 +145,36 @@ func New(lexer *lexer.Lexer) *Parser {
 	parser.registerInfixFunction(token.PERCENT, parser.parseInfixExpression)
 	parser.registerInfixFunction(token.LPAREN, parser.parseCallExpression)
 	parser.registerInfixFunction(token.LBRACKET, parser.parseIndexExpression)
+	parser.registerInfixFunction(token.ASSIGNMENT, parser.parseAssignExpression)
+	parser.registerInfixFunction(token.PLUS_ASSIGN, parser.parseAssignExpression)
+	parser.registerInfixFunction(token.MINUS_ASSIGN, parser.parseAssignExpression)
+	parser.registerInfixFunction(token.ASTERISK_ASSIGN, parser.parseAssignExpression)
+	parser.registerInfixFunction(token.SLASH_ASSIGN, parser.parseAssignExpression)
+	parser.registerInfixFunction(token.PERCENT_ASSIGN, parser.parseAssignExpression)
+	parser.registerInfixFunction(token.BIT_AND, parser.parseInfixExpression)
+	parser.registerInfixFunction(token.BIT_OR, parser.parseInfixExpression)
+	parser.registerInfixFunction(token.SHIFT_LEFT, parser.parseInfixExpression)
+	parser.registerInfixFunction(token.SHIFT_RIGHT, parser.parseInfixExpression)
+	parser.registerInfixFunction(token.AND, parser.parseLogicalExpression)
+	parser.registerInfixFunction(token.OR, parser.parseLogicalExpression)
+	parser.registerInfixFunction(token.NULLISH, parser.parseLogicalExpression)
 
 	return parser
 }
 
+// Errors returns the error diagnostics in a single line form each
 func (p *Parser) Errors() []string {
-	return p.errors
+	var errors []string
+	for _, diagnostic := range p.diagnostics {
+		if diagnostic.Severity == ERROR {
+			errors = append(errors, diagnostic.Error())
+		}
+	}
+	return errors
+}
+
+func (p *Parser) Diagnostics() []Diagnostic {
+	return p.diagnostics
 }
 
 func (p *Parser) nextToken() {
@@ -137,14 +198,78 @@ func (p *Parser) peekTokenIs(tokenType token.TokenType) bool {
 	return p.peekToken.Type == tokenType
 }
 
-func (p *Parser) peekError(tokenType token.TokenType) {
-	msg := fmt.Sprintf("Expected next token to be of type '%s'. Got '%s' -> %s",
-		tokenType, p.peekToken.Type, p.peekToken.Literal)
-	p.addError(msg)
+func describeTokenType(tokenType token.TokenType) string {
+	switch tokenType {
+	case token.EOF:
+		return "end of input"
+	case token.IDENTIFIER:
+		return "identifier"
+	case token.INT:
+		return "integer"
+	case token.FLOAT:
+		return "float"
+	case token.STRING:
+		return "string"
+	case token.TEMPLATE_MIDDLE, token.TEMPLATE_TAIL:
+		return "'}'"
+	}
+	return fmt.Sprintf("'%s'", tokenType)
 }
 
-func (p *Parser) addError(msg string) {
-	p.errors = append(p.errors, msg)
+func describeToken(tok token.Token) string {
+	switch tok.Type {
+	case token.EOF:
+		return "end of input"
+	case token.IDENTIFIER:
+		return fmt.Sprintf("identifier '%s'", tok.Literal)
+	case token.STRING:
+		return fmt.Sprintf("string \"%s\"", tok.Literal)
+	case token.TEMPLATE_MIDDLE, token.TEMPLATE_TAIL:
+		return "'}'"
+	}
+	return fmt.Sprintf("'%s'", tok.Literal)
+}
+
+func (p *Parser) peekError(tokenType token.TokenType) {
+	if p.peekTokenIs(token.ILLEGAL) {
+		p.illegalTokenError(p.peekToken)
+		return
+	}
+	msg := fmt.Sprintf("expected %s but found %s",
+		describeTokenType(tokenType), describeToken(p.peekToken))
+	p.addError(UNEXPECTED_TOKEN, p.peekToken, msg)
+}
+
+func (p *Parser) illegalTokenError(tok token.Token) {
+	p.addError(ILLEGAL_TOKEN, tok, tok.Literal)
+}
+
+func (p *Parser) addError(code string, tok token.Token, msg string) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Severity: ERROR,
+		Code:     code,
+		Start:    tok.Start,
+		End:      tok.End,
+		Message:  msg,
+		Source:   p.lexer.Line(tok.Start),
+	})
+}
+
+// synchronize discards tokens up to the end of the current statement so that
+// parsing can go on after an error
+func (p *Parser) synchronize() {
+	for !p.currentTokenIs(token.SEMICOLON) &&
+		!p.currentTokenIs(token.RBRACE) &&
+		!p.currentTokenIs(token.EOF) &&
+		!p.peekTokenIs(token.RBRACE) &&
+		!p.peekTokenIs(token.EOF) {
+		p.nextToken()
+	}
+	p.panicking = false
 }
 
 func (p *Parser) expectPeekToken(tokenType token.TokenType) bool {
@@ -174,8 +299,8 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 
-	// Empty Let definitions
-	if p.peekTokenIs(token.SEMICOLON) {
+	// Empty Let definitions. Constants must be given their value.
+	if p.peekTokenIs(token.SEMICOLON) && stmt.Token.Type != token.CONST {
 		p.nextToken()
 		return stmt
 	}
@@ -238,7 +363,12 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 
 	p.nextToken()
 
-	expr.Right = p.parseExpression(PREFIX)
+	// ^ binds tighter than a leading minus or ~, so that -2 ^ 2 is -(2 ^ 2)
+	if expr.Operator == token.MINUS || expr.Operator == token.BIT_NOT {
+		expr.Right = p.parseExpression(POWER - 1)
+	} else {
+		expr.Right = p.parseExpression(PREFIX)
+	}
 
 	return expr
 }
@@ -252,30 +382,183 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expr.Operator = p.currentToken.Literal
 
 	precedence := p.currentPrecedence()
+	// ^ is right associative: 2 ^ 3 ^ 2 is 2 ^ (3 ^ 2)
+	if expr.Operator == token.POWER {
+		precedence--
+	}
 
 	p.nextToken()
 
 	expr.Right = p.parseExpression(precedence)
 
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-		return expr
+	return expr
+}
+
+func (p *Parser) parseLogicalExpression(left ast.Expression) ast.Expression {
+	expr := &ast.LogicalExpression{
+		Token:    p.currentToken,
+		Left:     left,
+		Operator: p.currentToken.Literal,
 	}
 
+	precedence := p.currentPrecedence()
+	p.nextToken()
+	expr.Right = p.parseExpression(precedence)
+
+	return expr
+}
+
+// parseAssignExpression parses assignments, which are right associative so
+// that a = b = 1 assigns 1 to both a and b
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expr := &ast.AssignExpression{
+		Token:    p.currentToken,
+		Target:   target,
+		Operator: p.currentToken.Literal,
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case nil:
+		return nil
+	default:
+		p.addError(INVALID_ASSIGNMENT, p.currentToken,
+			fmt.Sprintf("cannot assign to %s", target.String()))
+		return nil
+	}
+
+	p.nextToken()
+
+	expr.Value = p.parseExpression(ASSIGN - 1)
+
 	return expr
 }
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currentToken.Type {
-	case token.LET:
+	case token.LET, token.CONST, token.VAR:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForInStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+// skipSemicolon makes the semicolon ending a statement optional
+func (p *Parser) skipSemicolon() {
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+}
+
+// parseLoopBody parses the block of a loop, where break and continue are
+// allowed
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loopDepth++
+	body := p.parseBlockStatement()
+	p.loopDepth--
+	return body
+}
+
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.currentToken}
+
+	if !p.expectPeekToken(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeekToken(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeekToken(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+	p.skipSemicolon()
+
+	return stmt
+}
+
+func (p *Parser) parseForInStatement() ast.Statement {
+	stmt := &ast.ForInStatement{Token: p.currentToken}
+
+	if !p.expectPeekToken(token.LPAREN) || !p.expectPeekToken(token.IDENTIFIER) {
+		return nil
+	}
+
+	stmt.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeekToken(token.IDENTIFIER) {
+			return nil
+		}
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	}
+
+	if !p.expectPeekToken(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeekToken(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeekToken(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+	p.skipSemicolon()
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.currentToken}
+	if p.loopDepth < 1 {
+		p.addError(MISPLACED_STATEMENT, p.currentToken, "'break' outside of a loop")
+		return nil
+	}
+	p.skipSemicolon()
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{Token: p.currentToken}
+	if p.loopDepth < 1 {
+		p.addError(MISPLACED_STATEMENT, p.currentToken, "'continue' outside of a loop")
+		return nil
+	}
+	p.skipSemicolon()
+	return stmt
+}
+
+func (p *Parser) parseIllegalToken() ast.Expression {
+	p.illegalTokenError(p.currentToken)
+	return nil
+}
+
 func (p *Parser) parseIdentifierExpression() ast.Expression {
 	return &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 }
@@ -287,12 +570,23 @@ func (p *Parser) parseBooleanLiteral() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	il := &ast.IntegerLiteral{Token: p.currentToken}
 
+	// The lexer has checked the digits and their separators. Prefixed literals
+	// such as 0xff have their base inferred.
 	base := 10
-	value, err := strconv.ParseInt(p.currentToken.Literal, base, 64)
+	literal := strings.ReplaceAll(p.currentToken.Literal, "_", "")
+	if len(literal) > 1 && literal[0] == '0' && !isDecimalDigit(literal[1]) {
+		base = 0
+	}
+	value, err := strconv.ParseInt(literal, base, 64)
 
-	if err != nil {
+	if errors.Is(err, strconv.ErrRange) {
+		msg := fmt.Sprintf("integer literal '%s' is too large, the largest integer literal is %d",
+			p.currentToken.Literal, math.MaxInt64)
+		p.addError(INVALID_LITERAL, p.currentToken, msg)
+		return nil
+	} else if err != nil {
 		msg := fmt.Sprintf("unable to parse '%s' as integer", p.currentToken.Literal)
-		p.addError(msg)
+		p.addError(INVALID_LITERAL, p.currentToken, msg)
 		return nil
 	}
 
@@ -301,8 +595,47 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return il
 }
 
+func isDecimalDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(p.currentToken.Literal, "_", ""), 64)
+	if err != nil {
+		msg := fmt.Sprintf("unable to parse '%s' as float", p.currentToken.Literal)
+		p.addError(INVALID_LITERAL, p.currentToken, msg)
+		return nil
+	}
+	return &ast.FloatLiteral{Token: p.currentToken, Value: value}
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
-	return &ast.StringLiteral{Value: p.currentToken.Literal}
+	return &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
+}
+
+// parseTemplateLiteral parses the expressions of a template string along with
+// the parts of the string between them, up to its tail
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	template := &ast.TemplateLiteral{Token: p.currentToken, Strings: []string{p.currentToken.Literal}}
+	for {
+		p.nextToken()
+		expression := p.parseExpression(LOWEST)
+		if expression == nil {
+			return nil
+		}
+		template.Expressions = append(template.Expressions, expression)
+
+		if !p.peekTokenIs(token.TEMPLATE_MIDDLE) && !p.peekTokenIs(token.TEMPLATE_TAIL) {
+			p.peekError(token.TEMPLATE_TAIL)
+			return nil
+		}
+		p.nextToken()
+		template.Strings = append(template.Strings, p.currentToken.Literal)
+		if p.currentTokenIs(token.TEMPLATE_TAIL) {
+			template.Tail = p.currentToken
+			return template
+		}
+	}
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
@@ -324,13 +657,27 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.currentTokenIs(token.RBRACE) {
+		if p.currentTokenIs(token.EOF) {
+			p.addError(UNEXPECTED_TOKEN, p.currentToken,
+				fmt.Sprintf("expected %s but found %s",
+					describeTokenType(token.RBRACE), describeToken(p.currentToken)))
+			return bs
+		}
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if p.panicking {
+			p.synchronize()
+			// The closing brace of the block has been consumed by the broken statement
+			if p.currentTokenIs(token.RBRACE) {
+				break
+			}
+		} else if stmt != nil {
 			bs.Statements = append(bs.Statements, stmt)
 		}
 		p.nextToken()
 	}
 
+	bs.Rbrace = p.currentToken
+
 	return bs
 }
 
@@ -411,7 +758,11 @@ func (p *Parser) parseFunctionExpression() ast.Expression {
 		return nil
 	}
 
+	// Loops around the function literal do not extend into its body
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
 	funcExp.Body = p.parseBlockStatement()
+	p.loopDepth = loopDepth
 
 	return funcExp
 }
@@ -420,16 +771,17 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	arrayLiteral := &ast.ArrayLiteral{Token: p.currentToken}
 
 	arrayLiteral.Items = p.parseExpressionList(token.RBRACKET)
+	arrayLiteral.Rbracket = p.currentToken
 
 	return arrayLiteral
 }
 
 func (p *Parser) parseHashLiteralExpression() ast.Expression {
 	hashLiteral := &ast.HashLiteral{Token: p.currentToken}
-	hashLiteral.Pairs = make(map[ast.Expression]ast.Expression)
 
 	if p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
+		hashLiteral.Rbrace = p.currentToken
 		return hashLiteral
 	}
 
@@ -441,7 +793,7 @@ func (p *Parser) parseHashLiteralExpression() ast.Expression {
 		}
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
-		hashLiteral.Pairs[key] = value
+		hashLiteral.Pairs = append(hashLiteral.Pairs, ast.HashPair{Key: key, Value: value})
 		if p.peekTokenIs(token.COMMA) {
 			p.nextToken()
 		}
@@ -451,6 +803,8 @@ func (p *Parser) parseHashLiteralExpression() ast.Expression {
 		return nil
 	}
 
+	hashLiteral.Rbrace = p.currentToken
+
 	return hashLiteral
 }
 
@@ -483,12 +837,13 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	callExp := &ast.CallExpression{Token: p.currentToken, Function: function}
 
 	callExp.Arguments = p.parseExpressionList(token.RPAREN)
+	callExp.Rparen = p.currentToken
 
 	return callExp
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	indexExpression := &ast.IndexExpression{Container: left}
+	indexExpression := &ast.IndexExpression{Token: p.currentToken, Container: left}
 
 	p.nextToken()
 
@@ -498,6 +853,8 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 		return nil
 	}
 
+	indexExpression.Rbracket = p.currentToken
+
 	return indexExpression
 }
 
@@ -506,9 +863,8 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefixParserFunction := p.prefixParserFunctions[tokenType]
 
 	if prefixParserFunction == nil {
-		msg := fmt.Sprintf("there is not a prefix parser function registered for token type %q",
-			tokenType)
-		p.addError(msg)
+		msg := fmt.Sprintf("expected an expression but found %s", describeToken(p.currentToken))
+		p.addError(EXPECTED_EXPRESSION, p.currentToken, msg)
 		return nil
 	}
 
@@ -532,8 +888,26 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{Statements: []ast.Statement{}}
 
 	for p.currentToken.Type != token.EOF {
+		if p.currentTokenIs(token.RBRACE) {
+			p.addError(UNEXPECTED_TOKEN, p.currentToken,
+				fmt.Sprintf("unexpected %s outside of a block", describeToken(p.currentToken)))
+			p.panicking = false
+			p.nextToken()
+			continue
+		}
 		statement := p.parseStatement()
-		if statement != nil {
+		if p.panicking {
+			p.synchronize()
+			// Unless the broken statement ended, the braces it stopped at belong
+			// to it, along with the semicolon ending it, rather than closing
+			// blocks which do not exist
+			for !p.currentTokenIs(token.SEMICOLON) && p.peekTokenIs(token.RBRACE) {
+				p.nextToken()
+				if p.peekTokenIs(token.SEMICOLON) {
+					p.nextToken()
+				}
+			}
+		} else if statement != nil {
 			program.Statements = append(program.Statements, statement)
 		}
 		p.nextToken()
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"node.go/ast"
+	"testing"
+)
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		code             string
+		expectedOperator string
+		expected         string
+	}{
+		{"x = 5", "=", "x = 5"},
+		{"x += y * 2;", "+=", "x += (y * 2)"},
+		{"x -= 1", "-=", "x -= 1"},
+		{"x *= 1", "*=", "x *= 1"},
+		{"x /= 1", "/=", "x /= 1"},
+		{"x %= 1", "%=", "x %= 1"},
+		{"a[1] = 2", "=", "(a[1]) = 2"},
+		{`h["k"] += 1`, "+=", "(h[k]) += 1"},
+		// Assignments are right associative
+		{"a = b = c", "=", "a = b = c"},
+		{"a = b == c", "=", "a = (b == c)"},
+	}
+
+	for _, test := range tests {
+		program := ParseTesting(t, test.code)
+		checkProgramStatements(t, program, 1)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("statement is not *ast.ExpressionStatement. Got %T", program.Statements[0])
+		}
+		expr, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("expression is not *ast.AssignExpression. Got %T", stmt.Expression)
+		}
+		if expr.Operator != test.expectedOperator {
+			t.Errorf("expected operator %s. Got %s", test.expectedOperator, expr.Operator)
+		}
+		if expr.String() != test.expected {
+			t.Errorf("expected %q. Got %q", test.expected, expr.String())
+		}
+	}
+}
+
+func TestInvalidAssignmentTarget(t *testing.T) {
+	tests := []struct {
+		code            string
+		expectedMessage string
+		expectedStart   string
+	}{
+		{"1 = 2", "cannot assign to 1", "test.ngo:1:3"},
+		{"a + b = c", "cannot assign to (a + b)", "test.ngo:1:7"},
+		{"f() += 1", "cannot assign to f()", "test.ngo:1:5"},
+	}
+
+	for _, test := range tests {
+		diagnostics := parseDiagnostics(test.code)
+		if len(diagnostics) != 1 {
+			t.Fatalf("%q expected to produce 1 diagnostic. Got %d: %v",
+				test.code, len(diagnostics), diagnostics)
+		}
+		if diagnostics[0].Code != INVALID_ASSIGNMENT {
+			t.Errorf("%q expected code %s. Got %s", test.code, INVALID_ASSIGNMENT, diagnostics[0].Code)
+		}
+		if diagnostics[0].Message != test.expectedMessage {
+			t.Errorf("%q expected message %q. Got %q", test.code, test.expectedMessage, diagnostics[0].Message)
+		}
+		if diagnostics[0].Start.String() != test.expectedStart {
+			t.Errorf("%q expected diagnostic at %s. Got %s", test.code, test.expectedStart, diagnostics[0].Start)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"node.go/ast"
+	"node.go/token"
+	"testing"
+)
+
+func TestConstAndVarStatements(t *testing.T) {
+	tests := []struct {
+		code          string
+		expectedType  token.TokenType
+		expectedName  string
+		expectedValue interface{}
+	}{
+		{"const answer = 42;", token.CONST, "answer", 42},
+		{"var flag = true", token.VAR, "flag", true},
+		{"var later;", token.VAR, "later", nil},
+	}
+
+	for _, test := range tests {
+		program := ParseTesting(t, test.code)
+		checkProgramStatements(t, program, 1)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("statement is not *ast.LetStatement. Got %T", program.Statements[0])
+		}
+		if stmt.Token.Type != test.expectedType {
+			t.Errorf("expected a %s declaration. Got %s", test.expectedType, stmt.Token.Type)
+		}
+		testIdentifier(t, stmt.Name, test.expectedName)
+		if test.expectedValue == nil {
+			if stmt.Value != nil {
+				t.Errorf("expected no value. Got %s", stmt.Value)
+			}
+		} else {
+			testLiteralExpression(t, stmt.Value, test.expectedValue)
+		}
+	}
+}
+
+func TestConstWithoutValue(t *testing.T) {
+	diagnostics := parseDiagnostics("const a;")
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. Got %d: %v", len(diagnostics), diagnostics)
+	}
+	if diagnostics[0].Message != "expected '=' but found ';'" {
+		t.Errorf("unexpected message %q", diagnostics[0].Message)
+	}
+	if diagnostics[0].Start.String() != "test.ngo:1:8" {
+		t.Errorf("expected diagnostic at test.ngo:1:8. Got %s", diagnostics[0].Start)
+	}
+}
+
+func TestVarNames(t *testing.T) {
+	program := ParseTesting(t, `var a = 1;
+	if (a) { var b = 2 } else { var a = 3 }
+	while (true) { var c; fn() { var hidden = 1 } }
+	for (x in []) { let d = 1; var e = 2 }
+	"${if (true) { var f = 1 }}"`)
+
+	expected := []string{"a", "b", "c", "e", "f"}
+	names := ast.VarNames(program.Statements)
+	if len(names) != len(expected) {
+		t.Fatalf("expected names %v. Got %v", expected, names)
+	}
+	for index, name := range expected {
+		if names[index] != name {
+			t.Errorf("expected name %d to be %s. Got %s", index, name, names[index])
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"node.go/lexer"
+	"testing"
+)
+
+func parseDiagnostics(code string) []Diagnostic {
+	par := New(lexer.NewWithFilename("test.ngo", code))
+	par.ParseProgram()
+	return par.Diagnostics()
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		code            string
+		expectedCode    string
+		expectedMessage string
+		expectedStart   string
+	}{
+		{"let x = (1 + 2;", UNEXPECTED_TOKEN, "expected ')' but found ';'", "test.ngo:1:15"},
+		{"let = 1", UNEXPECTED_TOKEN, "expected identifier but found '='", "test.ngo:1:5"},
+		{"1 +\n  * 2", EXPECTED_EXPRESSION, "expected an expression but found '*'", "test.ngo:2:3"},
+		{"let a = #", ILLEGAL_TOKEN, "unexpected character '#'", "test.ngo:1:9"},
+		{"let a = /* open", ILLEGAL_TOKEN, "unterminated block comment", "test.ngo:1:9"},
+		{"let a = \"open", ILLEGAL_TOKEN, "unterminated string", "test.ngo:1:9"},
+		{"\"${x \"y\"}\"", UNEXPECTED_TOKEN, "expected '}' but found string \"y\"", "test.ngo:1:6"},
+		{"\"${x", UNEXPECTED_TOKEN, "expected '}' but found end of input", "test.ngo:1:5"},
+		{"\"a ${} b\"", EXPECTED_EXPRESSION, "expected an expression but found '}'", "test.ngo:1:6"},
+		{"let big = 99999999999999999999", INVALID_LITERAL,
+			"integer literal '99999999999999999999' is too large, the largest integer literal is 9223372036854775807",
+			"test.ngo:1:11"},
+		{"let x = 0x;", ILLEGAL_TOKEN, "invalid hexadecimal literal '0x', it has no digits", "test.ngo:1:9"},
+		{"1__0", ILLEGAL_TOKEN, "invalid number literal '1__0', '_' must separate successive digits", "test.ngo:1:1"},
+		{"1 + 0b102", ILLEGAL_TOKEN, "invalid digit '2' in binary literal '0b102'", "test.ngo:1:5"},
+		{"fn(x) {", UNEXPECTED_TOKEN, "expected '}' but found end of input", "test.ngo:1:8"},
+		{"let a = 1 }", UNEXPECTED_TOKEN, "unexpected '}' outside of a block", "test.ngo:1:11"},
+	}
+
+	for _, test := range tests {
+		diagnostics := parseDiagnostics(test.code)
+		if len(diagnostics) != 1 {
+			t.Fatalf("%q expected to produce 1 diagnostic. Got %d: %v",
+				test.code, len(diagnostics), diagnostics)
+		}
+		diagnostic := diagnostics[0]
+		if diagnostic.Severity != ERROR {
+			t.Errorf("%q expected severity to be error. Got %s", test.code, diagnostic.Severity)
+		}
+		if diagnostic.Code != test.expectedCode {
+			t.Errorf("%q expected code %s. Got %s", test.code, test.expectedCode, diagnostic.Code)
+		}
+		if diagnostic.Message != test.expectedMessage {
+			t.Errorf("%q expected message %q. Got %q", test.code, test.expectedMessage, diagnostic.Message)
+		}
+		if diagnostic.Start.String() != test.expectedStart {
+			t.Errorf("%q expected diagnostic at %s. Got %s", test.code, test.expectedStart, diagnostic.Start)
+		}
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	diagnostics := parseDiagnostics("let a = 1;\n\tlet x = (a + 2;")
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. Got %d", len(diagnostics))
+	}
+	expected := "error[E0001]: expected ')' but found ';'\n" +
+		" --> test.ngo:2:16\n" +
+		"  |\n" +
+		"2 | \tlet x = (a + 2;\n" +
+		"  | \t              ^\n"
+	if diagnostics[0].String() != expected {
+		t.Fatalf("unexpected diagnostic rendering. Expected\n%s\nGot\n%s",
+			expected, diagnostics[0].String())
+	}
+}
+
+func TestDiagnosticUnderlinesWholeToken(t *testing.T) {
+	diagnostics := parseDiagnostics(`let 12345 = 1`)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. Got %d", len(diagnostics))
+	}
+	if diagnostics[0].underline() != "^^^^^" {
+		t.Fatalf("expected underline to span the token. Got %q", diagnostics[0].underline())
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		code           string
+		expectedErrors int
+	}{
+		{"let = 1; let b = 2; let = 3;", 2},
+		{"let x = (1 + 2; let y = 3;", 1},
+		{"fn() { let = 1; let b = ; b }; let c = 3", 2},
+		{"if (x) { 1 + } else { 2 }; let d = 4", 1},
+		{"let a = [1, 2; let b = 3; b", 1},
+		{"if (x { 1 }", 1},
+		{"let a = 1 }", 1},
+		{"if (x { if (y) { 1 } }; let b = 2", 1},
+		{"let = 1; } let b = 2", 2},
+	}
+
+	for _, test := range tests {
+		diagnostics := parseDiagnostics(test.code)
+		if len(diagnostics) != test.expectedErrors {
+			t.Errorf("%q expected to produce %d diagnostics. Got %d:", test.code,
+				test.expectedErrors, len(diagnostics))
+			for _, diagnostic := range diagnostics {
+				t.Errorf("%s", diagnostic.String())
+			}
+		}
+	}
+}
+
+func TestErrorRecoveryKeepsValidStatements(t *testing.T) {
+	par := New(lexer.New("let = 1; let b = 2; b"))
+	program := par.ParseProgram()
+	if len(par.Errors()) != 1 {
+		t.Fatalf("expected 1 error. Got %d", len(par.Errors()))
+	}
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements to be recovered. Got %d", len(program.Statements))
+	}
+	if program.String() != "let b = 2;b" {
+		t.Fatalf("unexpected recovered program %q", program.String())
+	}
+}
+
+func TestDiagnosticAlignsWithNonASCII(t *testing.T) {
+	diagnostics := parseDiagnostics(`let año = "ññññ" + ;`)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. Got %d", len(diagnostics))
+	}
+	expected := "error[E0002]: expected an expression but found ';'\n" +
+		" --> test.ngo:1:20\n" +
+		"  |\n" +
+		"1 | let año = \"ññññ\" + ;\n" +
+		"  |                    ^\n"
+	if diagnostics[0].String() != expected {
+		t.Fatalf("unexpected diagnostic rendering. Expected\n%s\nGot\n%s",
+			expected, diagnostics[0].String())
+	}
+}
@@ -7,16 +7,20 @@ import (
 
 func TestHashLiteralStringKeys(t *testing.T) {
 	tests := []struct {
-		code     string
-		expected map[string]int64
+		code           string
+		expectedKeys   []string
+		expectedValues []int64
 	}{
 		{
 			`{"key": 0, "hello": 3, "negative": 1}`,
-			map[string]int64{
-				"key":      0,
-				"hello":    3,
-				"negative": 1,
-			},
+			[]string{"key", "hello", "negative"},
+			[]int64{0, 3, 1},
+		},
+		// Pairs are kept in source order
+		{
+			`{"b": 2, "a": 1, "c": 3}`,
+			[]string{"b", "a", "c"},
+			[]int64{2, 1, 3},
 		},
 	}
 
@@ -32,18 +36,20 @@ func TestHashLiteralStringKeys(t *testing.T) {
 			t.Fatalf("exp is not HashLiteral. Got %T(%+v)",
 				stmt.Expression, stmt.Expression)
 		}
-		if len(test.expected) != len(exp.Pairs) {
+		if len(test.expectedKeys) != len(exp.Pairs) {
 			t.Fatalf("HashLiteral has an unexpected number of items. Expected %d, Got %d",
-				len(test.expected), len(exp.Pairs))
+				len(test.expectedKeys), len(exp.Pairs))
 		}
-		for k, v := range exp.Pairs {
-			literal, ok := k.(*ast.StringLiteral)
+		for index, pair := range exp.Pairs {
+			literal, ok := pair.Key.(*ast.StringLiteral)
 			if !ok {
 				t.Fatalf("HashLiteral key is not StringLiteral'")
 			}
-			stringL := literal.String()
-			expected := test.expected[stringL]
-			testIntegerLiteralExpression(t, v, expected)
+			if literal.String() != test.expectedKeys[index] {
+				t.Errorf("HashLiteral key %d expected to be %s. Got %s",
+					index, test.expectedKeys[index], literal.String())
+			}
+			testIntegerLiteralExpression(t, pair.Value, test.expectedValues[index])
 		}
 	}
 }
@@ -97,11 +103,11 @@ func TestParseHashLiteralWithInfixAndPrefixExpressions(t *testing.T) {
 			0, len(hashLiteral.Pairs))
 	}
 
-	for k, v := range hashLiteral.Pairs {
-		function, ok := tests[k.String()]
+	for _, pair := range hashLiteral.Pairs {
+		function, ok := tests[pair.Key.String()]
 		if !ok {
-			t.Fatalf("HashLiteral has an unexpected key %s", k.String())
+			t.Fatalf("HashLiteral has an unexpected key %s", pair.Key.String())
 		}
-		function(v)
+		function(pair.Value)
 	}
 }
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"node.go/ast"
+	"testing"
+)
+
+func TestWhileStatement(t *testing.T) {
+	program := ParseTesting(t, `while (x < 10) { x; break; continue }`)
+	checkProgramStatements(t, program, 1)
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("statement is not *ast.WhileStatement. Got %T", program.Statements[0])
+	}
+	testInfixExpression(t, stmt.Condition, "x", "<", 10)
+	if len(stmt.Body.Statements) != 3 {
+		t.Fatalf("expected 3 statements in the body. Got %d", len(stmt.Body.Statements))
+	}
+	if _, ok := stmt.Body.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("statement is not *ast.BreakStatement. Got %T", stmt.Body.Statements[1])
+	}
+	if _, ok := stmt.Body.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("statement is not *ast.ContinueStatement. Got %T", stmt.Body.Statements[2])
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		code          string
+		expectedKey   string
+		expectedValue string
+		expected      string
+	}{
+		{"for (x in items) { x }", "", "x", "for (x in items) {x}"},
+		{"for (k, v in hash) { v };", "k", "v", "for (k, v in hash) {v}"},
+	}
+
+	for _, test := range tests {
+		program := ParseTesting(t, test.code)
+		checkProgramStatements(t, program, 1)
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+		if !ok {
+			t.Fatalf("statement is not *ast.ForInStatement. Got %T", program.Statements[0])
+		}
+		if test.expectedKey == "" && stmt.Key != nil {
+			t.Errorf("expected no key variable. Got %s", stmt.Key)
+		}
+		if test.expectedKey != "" {
+			testIdentifier(t, stmt.Key, test.expectedKey)
+		}
+		testIdentifier(t, stmt.Value, test.expectedValue)
+		if stmt.String() != test.expected {
+			t.Errorf("expected %q. Got %q", test.expected, stmt.String())
+		}
+	}
+}
+
+func TestMisplacedLoopStatements(t *testing.T) {
+	tests := []struct {
+		code            string
+		expectedMessage string
+		expectedStart   string
+	}{
+		{"break", "'break' outside of a loop", "test.ngo:1:1"},
+		{"if (true) { continue }", "'continue' outside of a loop", "test.ngo:1:13"},
+		{"while (true) { fn() { break } }", "'break' outside of a loop", "test.ngo:1:23"},
+	}
+
+	for _, test := range tests {
+		diagnostics := parseDiagnostics(test.code)
+		if len(diagnostics) != 1 {
+			t.Fatalf("%q expected to produce 1 diagnostic. Got %d: %v",
+				test.code, len(diagnostics), diagnostics)
+		}
+		if diagnostics[0].Code != MISPLACED_STATEMENT {
+			t.Errorf("%q expected code %s. Got %s", test.code, MISPLACED_STATEMENT, diagnostics[0].Code)
+		}
+		if diagnostics[0].Message != test.expectedMessage {
+			t.Errorf("%q expected message %q. Got %q", test.code, test.expectedMessage, diagnostics[0].Message)
+		}
+		if diagnostics[0].Start.String() != test.expectedStart {
+			t.Errorf("%q expected diagnostic at %s. Got %s", test.code, test.expectedStart, diagnostics[0].Start)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"node.go/ast"
+	"testing"
+)
+
+func TestNodePositions(t *testing.T) {
+	tests := []struct {
+		code          string
+		expectedStart string
+		expectedEnd   string
+	}{
+		{`"hello"`, "1:1", "1:8"},
+		{`arr[1 + 2]`, "1:1", "1:11"},
+		{`sum(1, 2)`, "1:1", "1:10"},
+		{`1 + 2 * 3`, "1:1", "1:10"},
+		{`-x`, "1:1", "1:3"},
+		{`[1, 2]`, "1:1", "1:7"},
+		{`{"a": 1}`, "1:1", "1:9"},
+		{"fn(x) {\n  x\n}", "1:1", "3:2"},
+		{"if (x) { 1 } else { 2 }", "1:1", "1:24"},
+		{"  let a = 1", "1:3", "1:12"},
+		{"return;", "1:1", "1:7"},
+	}
+
+	for _, test := range tests {
+		program := ParseTesting(t, test.code)
+		var node ast.Node = program.Statements[0]
+		if node.Pos().String() != test.expectedStart {
+			t.Errorf("%q expected to start at %s. Got %s",
+				test.code, test.expectedStart, node.Pos())
+		}
+		if node.End().String() != test.expectedEnd {
+			t.Errorf("%q expected to end at %s. Got %s",
+				test.code, test.expectedEnd, node.End())
+		}
+	}
+}
+
+func TestIndexExpressionToken(t *testing.T) {
+	program := ParseTesting(t, "a\n[0]")
+	stmt := testExpressionStatement(t, program.Statements[0])
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("expected IndexExpression. Got %T", stmt.Expression)
+	}
+	if indexExp.Token.Literal != "[" {
+		t.Fatalf("expected IndexExpression.Token to be '['. Got %q", indexExp.Token.Literal)
+	}
+	if indexExp.Token.Start.String() != "2:1" {
+		t.Fatalf("expected IndexExpression.Token at 2:1. Got %s", indexExp.Token.Start)
+	}
+}
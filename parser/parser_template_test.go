@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"node.go/ast"
+	"node.go/lexer"
+	"testing"
+)
+
+func TestTemplateLiteral(t *testing.T) {
+	tests := []struct {
+		input               string
+		expectedStrings     []string
+		expectedExpressions []string
+	}{
+		{`"hello ${name}"`, []string{"hello ", ""}, []string{"name"}},
+		{`"${a}${b}"`, []string{"", "", ""}, []string{"a", "b"}},
+		{`"sum: ${a + b * 2}, next: ${f(1)}!"`, []string{"sum: ", ", next: ", "!"}, []string{"(a + (b * 2))", "f(1)"}},
+		{`"${ {"k": "${v}"}["k"] }"`, []string{"", ""}, []string{`({k: "${v}"}[k])`}},
+		{`"a\${b} ${c}"`, []string{"a${b} ", ""}, []string{"c"}},
+	}
+
+	for _, test := range tests {
+		par := New(lexer.New(test.input))
+		program := par.ParseProgram()
+		checkParserErrors(t, par)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. Got %T", program.Statements[0])
+		}
+		template, ok := stmt.Expression.(*ast.TemplateLiteral)
+		if !ok {
+			t.Fatalf("%s: expression is not *ast.TemplateLiteral. Got %T", test.input, stmt.Expression)
+		}
+		if len(template.Strings) != len(test.expectedStrings) {
+			t.Fatalf("%s: expected strings %q. Got %q", test.input, test.expectedStrings, template.Strings)
+		}
+		for index, expected := range test.expectedStrings {
+			if template.Strings[index] != expected {
+				t.Errorf("%s: expected string %d to be %q. Got %q", test.input, index, expected, template.Strings[index])
+			}
+		}
+		if len(template.Expressions) != len(test.expectedExpressions) {
+			t.Fatalf("%s: expected %d expressions. Got %d", test.input,
+				len(test.expectedExpressions), len(template.Expressions))
+		}
+		for index, expected := range test.expectedExpressions {
+			if actual := template.Expressions[index].String(); actual != expected {
+				t.Errorf("%s: expected expression %d to be %s. Got %s", test.input, index, expected, actual)
+			}
+		}
+		if template.End().Offset != len(test.input) {
+			t.Errorf("%s: expected the template to end at %d. Got %d", test.input, len(test.input), template.End().Offset)
+		}
+	}
+}
@@ -380,6 +380,64 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	testIntegerLiteralExpression(t, stmt.Expression, 2)
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int64
+	}{
+		{"0xff;", 255},
+		{"0XFF;", 255},
+		{"0o755;", 493},
+		{"0b1010;", 10},
+		{"1_000_000;", 1000000},
+		{"0x7fff_ffff_ffff_ffff;", 9223372036854775807},
+		{"0755;", 755},
+	}
+
+	for _, test := range tests {
+		par := New(lexer.New(test.code))
+		program := par.ParseProgram()
+		checkParserErrors(t, par)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expression is not *ast.IntegerLiteral. Got %T", stmt.Expression)
+		}
+		if literal.Value != test.expected {
+			t.Errorf("%s: IntegerLiteral.Value is not %d. Got %d", test.code, test.expected, literal.Value)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{".5;", 0.5},
+		{"1e-9;", 1e-9},
+		{"1_000.5;", 1000.5},
+	}
+
+	for _, test := range tests {
+		par := New(lexer.New(test.code))
+		program := par.ParseProgram()
+		checkParserErrors(t, par)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. Got %T", program.Statements[0])
+		}
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("expression is not *ast.FloatLiteral. Got %T", stmt.Expression)
+		}
+		if literal.Value != test.expected {
+			t.Errorf("FloatLiteral.Value is not %g. Got %g", test.expected, literal.Value)
+		}
+	}
+}
+
 func testStringLiteral(t *testing.T, exp ast.Expression, expected string) bool {
 	stringExp, ok := exp.(*ast.StringLiteral)
 	if !ok {
@@ -505,6 +563,27 @@ func TestOperatorPrecedence(t *testing.T) {
 		{"!1 ^ 2", "((!1) ^ 2)"},
 		{"1 + 2 + 3", "((1 + 2) + 3)"},
 		{"1 + 2 % 1 * 3 / 2 ^ 6", "(1 + (2 % ((1 * 3) / (2 ^ 6))))"},
+		{"2 ^ 3 ^ 2", "(2 ^ (3 ^ 2))"},
+		{"-2 ^ 2", "(-(2 ^ 2))"},
+		{"-2 ^ 2 * 3", "((-(2 ^ 2)) * 3)"},
+		{"-2 * 3", "((-2) * 3)"},
+		{"2 ^ -1", "(2 ^ (-1))"},
+		{"-a[0] ^ 2", "(-((a[0]) ^ 2))"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a ?? b || c", "(a ?? (b || c))"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a == 1 && !b", "((a == 1) && (!b))"},
+		{"x = a || b", "x = (a || b)"},
+		{"a | b & c", "(a | (b & c))"},
+		{"a & b == c", "(a & (b == c))"},
+		{"a || b | c", "(a || (b | c))"},
+		{"1 << 2 + 3", "(1 << (2 + 3))"},
+		{"a < b << c", "(a < (b << c))"},
+		{"a >> 1 << 2", "((a >> 1) << 2)"},
+		{"2 ^ 3 << 1", "((2 ^ 3) << 1)"},
+		{"~2 ^ 2", "(~(2 ^ 2))"},
+		{"~a & b", "((~a) & b)"},
 		{"1 > 2 >= 3 < 4 <= 5", "((((1 > 2) >= 3) < 4) <= 5)"},
 		{"1 + 2 * 3", "(1 + (2 * 3))"},
 		{"(1 + 2) * 3", "((1 + 2) * 3)"},
@@ -3,7 +3,7 @@ package repl
 import (
 	"bufio"
 	"io"
-	"node.go/evaluator"
+	"node.go/engine"
 	"node.go/lexer"
 	"node.go/object"
 	"node.go/parser"
@@ -23,13 +23,20 @@ func printPrompt(out io.Writer, lastStatusCode byte) {
 }
 
 func Start(in io.Reader, out io.Writer) {
+	StartWithEngine(in, out, engine.NewEvaluator())
+}
+
+// StartWithEngine runs the repl on the given engine
+func StartWithEngine(in io.Reader, out io.Writer, eng engine.Engine) {
 	scanner := bufio.NewScanner(in)
 	var lastStatus byte = 0
-	var environment = object.NewEnvironment()
 
 	for {
 		printPrompt(out, lastStatus)
-		_ = scanner.Scan()
+		if !scanner.Scan() {
+			io.WriteString(out, "\n")
+			return
+		}
 
 		line := scanner.Text()
 		lex := lexer.New(line)
@@ -38,21 +45,25 @@ func Start(in io.Reader, out io.Writer) {
 
 		if len(par.Errors()) > 0 {
 			lastStatus = 1
-			for _, errorMessage := range par.Errors() {
-				io.WriteString(out, errorMessage)
-				io.WriteString(out, "\n")
+			for _, diagnostic := range par.Diagnostics() {
+				io.WriteString(out, diagnostic.String())
 			}
 			io.WriteString(out, "\n")
 		} else {
-			evaluatedObject := evaluator.Eval(program, environment)
-			if evaluatedObject.Type() == object.ERROR {
+			evaluatedObject := eng.Run(program)
+			if evaluatedObject == nil {
+				// Nothing to evaluate, as with empty lines
+				evaluatedObject = object.NULL
+			}
+			io.WriteString(out, "\n")
+			if err, ok := evaluatedObject.(*object.Error); ok {
 				lastStatus = 1
+				io.WriteString(out, err.Traceback())
 			} else {
 				lastStatus = 0
+				io.WriteString(out, evaluatedObject.Inspect())
 			}
 			io.WriteString(out, "\n")
-			io.WriteString(out, evaluatedObject.Inspect())
-			io.WriteString(out, "\n")
 		}
 	}
 }
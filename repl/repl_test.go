@@ -0,0 +1,32 @@
+package repl
+
+import (
+	"bytes"
+	"node.go/engine"
+	"strings"
+	"testing"
+)
+
+func TestReplPrintsEachLine(t *testing.T) {
+	input := "let a = 1;\nconst b = a + 1\n\nvar c;\na + b\n"
+	expected := []string{"null", "null", "null", "null", "3"}
+
+	for _, name := range engine.Names {
+		eng, err := engine.New(name)
+		if err != nil {
+			t.Fatalf("unable to create the %s engine: %s", name, err)
+		}
+		var out bytes.Buffer
+		StartWithEngine(strings.NewReader(input), &out, eng)
+
+		var results []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if line != "" && !strings.HasPrefix(line, PROMPT) {
+				results = append(results, line)
+			}
+		}
+		if strings.Join(results, ",") != strings.Join(expected, ",") {
+			t.Errorf("%s: expected results %v. Got %v", name, expected, results)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"io"
+	"node.go/engine"
+	"node.go/lexer"
+	"node.go/object"
+	"node.go/parser"
+)
+
+// Exit statuses returned by Run
+const (
+	ExitOK           = 0
+	ExitRuntimeError = 1
+	ExitSyntaxError  = 2
+)
+
+// ARGS is the name of the global binding holding the script arguments
+const ARGS = "args"
+
+// Run evaluates a whole program and reports its outcome through the given
+// writers. Script arguments are exposed to the program as an array of strings
+// bound to ARGS. The result of the program is written to out unless it is
+// null. Parser diagnostics and runtime tracebacks are written to errOut and
+// turned into a non-zero exit status.
+func Run(name string, code string, args []string, out io.Writer, errOut io.Writer) int {
+	return RunWithEngine(engine.NewEvaluator(), name, code, args, out, errOut)
+}
+
+// RunWithEngine is Run on the given engine
+func RunWithEngine(eng engine.Engine, name string, code string, args []string, out io.Writer, errOut io.Writer) int {
+	lex := lexer.NewWithFilename(name, code)
+	par := parser.New(lex)
+	program := par.ParseProgram()
+
+	if len(par.Errors()) > 0 {
+		for _, diagnostic := range par.Diagnostics() {
+			io.WriteString(errOut, diagnostic.String())
+		}
+		return ExitSyntaxError
+	}
+
+	eng.Define(ARGS, argumentsToArray(args))
+
+	evaluated := eng.Run(program)
+	if evaluated == nil {
+		return ExitOK
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		io.WriteString(errOut, err.Traceback())
+		io.WriteString(errOut, "\n")
+		return ExitRuntimeError
+	}
+	if evaluated != object.NULL {
+		io.WriteString(out, evaluated.Inspect())
+		io.WriteString(out, "\n")
+	}
+	return ExitOK
+}
+
+func argumentsToArray(args []string) *object.Array {
+	items := make([]object.Object, len(args))
+	for index, arg := range args {
+		items[index] = object.NewString(arg)
+	}
+	return object.NewArray(items)
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"bytes"
+	"node.go/engine"
+	"strings"
+	"testing"
+)
+
+func testRun(t *testing.T, code string, args []string) (int, string, string) {
+	var out, errOut bytes.Buffer
+	status := Run("test.ngo", code, args, &out, &errOut)
+	return status, out.String(), errOut.String()
+}
+
+func TestRunPrintsResult(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"1 + 2", "3\n"},
+		{`"a" + "b"`, "'ab'\n"},
+		{"let a = 1;", ""},
+		{"if (false) {1}", ""},
+	}
+
+	for _, test := range tests {
+		status, out, errOut := testRun(t, test.code, nil)
+		if status != ExitOK {
+			t.Fatalf("expected exit status %d. Got %d (%s)", ExitOK, status, errOut)
+		}
+		if out != test.expected {
+			t.Fatalf("expected output %q. Got %q", test.expected, out)
+		}
+	}
+}
+
+func TestRunExposesArguments(t *testing.T) {
+	status, out, _ := testRun(t, `len(args) + 0; args[1]`, []string{"first", "second"})
+	if status != ExitOK {
+		t.Fatalf("expected exit status %d. Got %d", ExitOK, status)
+	}
+	if out != "'second'\n" {
+		t.Fatalf("expected output %q. Got %q", "'second'\n", out)
+	}
+}
+
+func TestRunExitStatus(t *testing.T) {
+	tests := []struct {
+		code           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"let = 1", ExitSyntaxError, "error[E0001]: expected identifier but found '='"},
+		{"a + 1", ExitRuntimeError, "Traceback (most recent call last):\n" +
+			"  test.ngo:1:1, in <program>\n" +
+			"ERROR: reference error: a is not defined\n"},
+		{"1 + true", ExitRuntimeError, "Traceback (most recent call last):\n" +
+			"  test.ngo:1:1, in <program>\n" +
+			"ERROR: type mismatch: INTEGER + BOOLEAN\n"},
+	}
+
+	for _, test := range tests {
+		status, out, errOut := testRun(t, test.code, nil)
+		if status != test.expectedStatus {
+			t.Fatalf("expected exit status %d. Got %d", test.expectedStatus, status)
+		}
+		if out != "" {
+			t.Fatalf("expected no output. Got %q", out)
+		}
+		if !strings.HasPrefix(errOut, test.expectedError) {
+			t.Fatalf("expected error output to start with %q. Got %q", test.expectedError, errOut)
+		}
+	}
+}
+
+func TestRunWithVM(t *testing.T) {
+	var out, errOut bytes.Buffer
+	code := "let double = fn(x) { x * 2 }; double(len(args))"
+	status := RunWithEngine(engine.NewVM(), "test.ngo", code, []string{"a", "b"}, &out, &errOut)
+	if status != ExitOK {
+		t.Fatalf("expected exit status %d. Got %d (%s)", ExitOK, status, errOut.String())
+	}
+	if out.String() != "4\n" {
+		t.Fatalf("expected output %q. Got %q", "4\n", out.String())
+	}
+}
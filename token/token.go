@@ -1,18 +1,28 @@
 package token
 
+import "fmt"
+
 const (
 	// MISC
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // never returned as a token, see Lexer.Comments
 	// Identifiers
 	IDENTIFIER = "ident"
 
 	// Literals
 	INT    = "int"
+	FLOAT  = "float"
 	STRING = "string"
 	TRUE   = "true"
 	FALSE  = "false"
 
+	// parts of a template string such as "a ${x} b ${y} c": its head "a ", its
+	// middles " b " and its tail " c", between which expressions are lexed
+	TEMPLATE_HEAD   = "template head"
+	TEMPLATE_MIDDLE = "template middle"
+	TEMPLATE_TAIL   = "template tail"
+
 	// operators
 	PLUS     = "+"
 	MINUS    = "-"
@@ -28,17 +38,39 @@ const (
 	PERCENT  = "%"
 	POWER    = "^"
 
-	//
-	ASSIGNMENT = "="
+	// bitwise operators. ^ being the power operator, there is no exclusive or.
+	BIT_AND     = "&"
+	BIT_OR      = "|"
+	BIT_NOT     = "~"
+	SHIFT_LEFT  = "<<"
+	SHIFT_RIGHT = ">>"
+
+	// logical operators, which evaluate their right operand lazily
+	AND     = "&&"
+	OR      = "||"
+	NULLISH = "??"
+
+	// assignments
+	ASSIGNMENT      = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
 
 	// keywords
-	VAR    = "var"
-	CONST  = "const"
-	LET    = "let"
-	FUNC   = "function"
-	IF     = "if"
-	ELSE   = "else"
-	RETURN = "return"
+	VAR      = "var"
+	CONST    = "const"
+	LET      = "let"
+	FUNC     = "function"
+	IF       = "if"
+	ELSE     = "else"
+	RETURN   = "return"
+	WHILE    = "while"
+	FOR      = "for"
+	IN       = "in"
+	BREAK    = "break"
+	CONTINUE = "continue"
 
 	// Delimiters
 	COMMA     = ","
@@ -63,10 +95,29 @@ var keywords = map[string]TokenType{
 	"if":       IF,
 	"else":     ELSE,
 	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
 	"true":     TRUE,
 	"false":    FALSE,
 }
 
+var compoundAssignments = map[string]string{
+	PLUS_ASSIGN:     PLUS,
+	MINUS_ASSIGN:    MINUS,
+	ASTERISK_ASSIGN: ASTERISK,
+	SLASH_ASSIGN:    SLASH,
+	PERCENT_ASSIGN:  PERCENT,
+}
+
+// CompoundOperator returns the operator a compound assignment such as += applies
+func CompoundOperator(assignment string) (string, bool) {
+	operator, ok := compoundAssignments[assignment]
+	return operator, ok
+}
+
 func LookupKeyword(literal string) TokenType {
 	if tt, ok := keywords[literal]; ok {
 		return tt
@@ -74,9 +125,42 @@ func LookupKeyword(literal string) TokenType {
 	return IDENTIFIER
 }
 
+// Position locates a character within the source code
+type Position struct {
+	Filename string
+	Line     int // starting at 1
+	Column   int // starting at 1
+	Offset   int // bytes, starting at 0
+}
+
+// IsValid tells whether the position has been set at all
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	var location string
+	if p.IsValid() {
+		location = fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if p.Filename == "" {
+		if location == "" {
+			return "-"
+		}
+		return location
+	}
+	if location == "" {
+		return p.Filename
+	}
+	return p.Filename + ":" + location
+}
+
 type Token struct {
 	Type    TokenType
 	Literal string
+
+	Start Position // position of the first character of the token
+	End   Position // position immediately after the token
 }
 
 func New(tokenType TokenType, literal string) *Token {
@@ -0,0 +1,29 @@
+package vm
+
+import "node.go/object"
+
+// CELL is the type of the variables captured by closures, which are only ever
+// read through
+const CELL object.Type = "CELL"
+
+// cell holds a variable captured by a closure, so that the closure and the
+// function defining the variable see the assignments of one another
+type cell struct {
+	value object.Object
+}
+
+func (c *cell) Type() object.Type {
+	return CELL
+}
+
+func (c *cell) Inspect() string {
+	return orNull(c.value).Inspect()
+}
+
+// deref reads through the cell the slot may hold
+func deref(slot object.Object) object.Object {
+	if c, ok := slot.(*cell); ok {
+		return orNull(c.value)
+	}
+	return orNull(slot)
+}
@@ -0,0 +1,34 @@
+package vm
+
+import (
+	"node.go/code"
+	"node.go/object"
+	"node.go/token"
+)
+
+// Frame is a running call of a closure
+type Frame struct {
+	closure     *object.Closure
+	ip          int // offset of the next instruction to run
+	basePointer int // stack slot of the first local
+}
+
+func NewFrame(closure *object.Closure, basePointer int) *Frame {
+	return &Frame{closure: closure, basePointer: basePointer}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.closure.Fn.Instructions
+}
+
+// position locates the instruction starting at the given offset
+func (f *Frame) position(offset int) token.Position {
+	return f.closure.Fn.Positions.Lookup(offset)
+}
+
+func (f *Frame) name() string {
+	if f.closure.Fn.Name == "" {
+		return object.ANONYMOUS
+	}
+	return f.closure.Fn.Name
+}
@@ -0,0 +1,39 @@
+package vm
+
+import (
+	"node.go/evaluator"
+	"node.go/object"
+)
+
+// ITERATOR is the type of the iterators of for-in loops, which never leave the
+// stack of the virtual machine
+const ITERATOR object.Type = "ITERATOR"
+
+// iterator holds the items of the iterable a for-in loop goes through
+type iterator struct {
+	keys   []object.Object
+	values []object.Object
+	both   bool // whether keys are pushed along with values
+	next   int
+}
+
+func newIterator(iterable object.Object, both bool) object.Object {
+	it := &iterator{both: both}
+	err := evaluator.Iterate(iterable, both, func(key object.Object, value object.Object) bool {
+		it.keys = append(it.keys, key)
+		it.values = append(it.values, value)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return it
+}
+
+func (it *iterator) Type() object.Type {
+	return ITERATOR
+}
+
+func (it *iterator) Inspect() string {
+	return "<iterator>"
+}
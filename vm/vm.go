@@ -0,0 +1,468 @@
+package vm
+
+import (
+	"fmt"
+	"node.go/code"
+	"node.go/compiler"
+	"node.go/evaluator"
+	"node.go/object"
+	"node.go/token"
+	"strings"
+)
+
+const (
+	GLOBALS_SIZE   = 65536 // as many as OpGetGlobal can address
+	STACK_SIZE     = 2048  // initial size, the stack grows on demand
+	MAX_STACK_SIZE = 1 << 22
+	MAX_FRAMES     = 1 << 20
+	MAX_ARGUMENTS  = 255 // as many as OpCall can pass
+)
+
+type VM struct {
+	constants   []object.Object
+	globals     []object.Object
+	globalNames []string
+	builtins    object.Builtins
+
+	stack []object.Object
+	sp    int // next free slot: the top of the stack is stack[sp-1]
+
+	frames []*Frame
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithGlobalsStore(bytecode, make([]object.Object, GLOBALS_SIZE))
+}
+
+// NewWithGlobalsStore creates a virtual machine sharing the globals of a
+// previous run, as the repl does between lines
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, globals []object.Object) *VM {
+	main := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		Positions:    bytecode.Positions,
+		NumLocals:    bytecode.NumLocals,
+	}
+	frame := NewFrame(&object.Closure{Fn: main}, 0)
+
+	return &VM{
+		constants:   bytecode.Constants,
+		globals:     globals,
+		globalNames: bytecode.GlobalNames,
+		stack:       make([]object.Object, STACK_SIZE),
+		sp:          main.NumLocals,
+		frames:      []*Frame{frame},
+	}
+}
+
+// SetBuiltins gives the program builtins of its own, looked up by name when a
+// global has not been defined, as the compiler resolves them
+func (vm *VM) SetBuiltins(builtins object.Builtins) {
+	vm.builtins = builtins
+}
+
+// Call runs the function with the given arguments, on the constants and the
+// globals of the program that defined it and with its builtins
+func Call(bytecode *compiler.Bytecode, globals []object.Object, builtins object.Builtins,
+	function object.Object, arguments []object.Object) object.Object {
+	if len(arguments) > MAX_ARGUMENTS {
+		return object.NewError(fmt.Sprintf("type error: at most %d arguments can be passed. Got %d",
+			MAX_ARGUMENTS, len(arguments)))
+	}
+	call := &compiler.Bytecode{
+		Instructions: append(code.Make(code.OpCall, len(arguments)), code.Make(code.OpReturnValue)...),
+		Constants:    bytecode.Constants,
+		GlobalNames:  bytecode.GlobalNames,
+	}
+	machine := NewWithGlobalsStore(call, globals)
+	machine.SetBuiltins(builtins)
+	machine.push(function)
+	for _, argument := range arguments {
+		if err, ok := machine.push(argument).(*object.Error); ok {
+			return err
+		}
+	}
+	return machine.Run()
+}
+
+// Run executes the program and returns its value. Runtime errors are returned
+// as *object.Error, along with the position and the calls they were raised at.
+func (vm *VM) Run() object.Object {
+	for {
+		frame := vm.frames[len(vm.frames)-1]
+		instructions := frame.Instructions()
+		start := frame.ip
+		op := code.Opcode(instructions[start])
+		frame.ip++
+
+		var result object.Object
+
+		switch op {
+		case code.OpConstant:
+			index := code.ReadUint16(instructions[frame.ip:])
+			frame.ip += 2
+			result = vm.push(vm.constants[index])
+		case code.OpPop:
+			vm.sp--
+		case code.OpTrue:
+			result = vm.push(object.TRUE)
+		case code.OpFalse:
+			result = vm.push(object.FALSE)
+		case code.OpNull:
+			result = vm.push(object.NULL)
+		case code.OpPrefix:
+			operator := code.Operators[code.ReadUint8(instructions[frame.ip:])]
+			frame.ip++
+			right := vm.pop()
+			result = vm.push(evaluator.EvalPrefix(operator, right))
+		case code.OpInfix:
+			operator := code.Operators[code.ReadUint8(instructions[frame.ip:])]
+			frame.ip++
+			right := vm.pop()
+			left := vm.pop()
+			result = vm.push(evaluator.EvalInfix(operator, left, right))
+		case code.OpJump:
+			frame.ip = int(code.ReadUint16(instructions[frame.ip:]))
+		case code.OpJumpNotTruthy:
+			target := int(code.ReadUint16(instructions[frame.ip:]))
+			frame.ip += 2
+			if !evaluator.IsTruthy(vm.pop()) {
+				frame.ip = target
+			}
+		case code.OpJumpFalsy, code.OpJumpTruthy, code.OpJumpNotNull:
+			target := int(code.ReadUint16(instructions[frame.ip:]))
+			frame.ip += 2
+			if decides(code.Opcode(op), vm.stack[vm.sp-1]) {
+				frame.ip = target
+			} else {
+				vm.pop()
+			}
+		case code.OpGetGlobal:
+			index := code.ReadUint16(instructions[frame.ip:])
+			frame.ip += 2
+			value := vm.globals[index]
+			if value == nil {
+				value = vm.undefinedGlobal(int(index))
+			}
+			result = vm.push(value)
+		case code.OpSetGlobal:
+			index := code.ReadUint16(instructions[frame.ip:])
+			frame.ip += 2
+			vm.globals[index] = vm.pop()
+		case code.OpDeclareGlobal:
+			index := code.ReadUint16(instructions[frame.ip:])
+			frame.ip += 2
+			if vm.globals[index] == nil {
+				vm.globals[index] = object.NULL
+			}
+		case code.OpGetLocal:
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			result = vm.push(deref(vm.stack[frame.basePointer+int(index)]))
+		case code.OpSetLocal:
+			// A new binding, leaving alone the cell closures may have captured
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			vm.stack[frame.basePointer+int(index)] = vm.pop()
+		case code.OpGetFree:
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			result = vm.push(deref(frame.closure.Free[index]))
+		case code.OpAssignGlobal:
+			index := code.ReadUint16(instructions[frame.ip:])
+			frame.ip += 2
+			if vm.globals[index] == nil {
+				result = object.NewError(fmt.Sprintf("reference error: %s is not defined", vm.globalNames[index]))
+			} else {
+				vm.globals[index] = vm.stack[vm.sp-1]
+			}
+		case code.OpAssignLocal:
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			slot := frame.basePointer + int(index)
+			if c, ok := vm.stack[slot].(*cell); ok {
+				c.value = vm.stack[vm.sp-1]
+			} else {
+				vm.stack[slot] = vm.stack[vm.sp-1]
+			}
+		case code.OpAssignFree:
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			frame.closure.Free[index].(*cell).value = vm.stack[vm.sp-1]
+		case code.OpSetIndex:
+			operator := int(code.ReadUint8(instructions[frame.ip:]))
+			frame.ip++
+			value := vm.pop()
+			index := vm.pop()
+			container := vm.pop()
+			result = vm.push(vm.setIndex(container, index, operator, value))
+		case code.OpCaptureLocal:
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			slot := frame.basePointer + int(index)
+			c, ok := vm.stack[slot].(*cell)
+			if !ok {
+				c = &cell{value: vm.stack[slot]}
+				vm.stack[slot] = c
+			}
+			result = vm.push(c)
+		case code.OpCaptureFree:
+			index := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			result = vm.push(frame.closure.Free[index])
+		case code.OpArray:
+			count := int(code.ReadUint16(instructions[frame.ip:]))
+			frame.ip += 2
+			items := make([]object.Object, count)
+			copy(items, vm.stack[vm.sp-count:vm.sp])
+			vm.sp -= count
+			result = vm.push(object.NewArray(items))
+		case code.OpHash:
+			count := int(code.ReadUint16(instructions[frame.ip:]))
+			frame.ip += 2
+			hash := vm.buildHash(vm.stack[vm.sp-count : vm.sp])
+			vm.sp -= count
+			result = vm.push(hash)
+		case code.OpTemplate:
+			count := int(code.ReadUint16(instructions[frame.ip:]))
+			frame.ip += 2
+			var buffer strings.Builder
+			for _, part := range vm.stack[vm.sp-count : vm.sp] {
+				buffer.WriteString(object.Display(part))
+			}
+			vm.sp -= count
+			result = vm.push(object.NewString(buffer.String()))
+		case code.OpIndex:
+			index := vm.pop()
+			container := vm.pop()
+			result = vm.push(evaluator.EvalIndex(container, index))
+		case code.OpCall:
+			arguments := int(code.ReadUint8(instructions[frame.ip:]))
+			frame.ip++
+			result = vm.call(arguments)
+		case code.OpReturnValue:
+			value := vm.pop()
+			if len(vm.frames) == 1 {
+				return value
+			}
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			vm.sp = frame.basePointer - 1
+			result = vm.push(value)
+		case code.OpClosure:
+			index := code.ReadUint16(instructions[frame.ip:])
+			count := int(code.ReadUint8(instructions[frame.ip+2:]))
+			frame.ip += 3
+			result = vm.pushClosure(int(index), count)
+		case code.OpIterator:
+			variables := code.ReadUint8(instructions[frame.ip:])
+			frame.ip++
+			result = vm.push(newIterator(vm.pop(), variables == 2))
+		case code.OpIterNext:
+			target := int(code.ReadUint16(instructions[frame.ip:]))
+			frame.ip += 2
+			result = vm.iterate(vm.stack[vm.sp-1].(*iterator), frame, target)
+		case code.OpError:
+			index := code.ReadUint16(instructions[frame.ip:])
+			frame.ip += 2
+			result = object.NewError(vm.constants[index].(*object.String).Value)
+		default:
+			result = object.NewError(fmt.Sprintf("unknown opcode %d", op))
+		}
+
+		if err, ok := result.(*object.Error); ok {
+			return vm.unwind(err, start)
+		}
+	}
+}
+
+// push returns an error when the value is one, or when the stack is full, so
+// that instructions can report them right away
+func (vm *VM) push(obj object.Object) object.Object {
+	if vm.sp >= len(vm.stack) {
+		if !vm.growStack(vm.sp + 1) {
+			return stackOverflow()
+		}
+	}
+	vm.stack[vm.sp] = obj
+	vm.sp++
+	return obj
+}
+
+func (vm *VM) pop() object.Object {
+	vm.sp--
+	return vm.stack[vm.sp]
+}
+
+func (vm *VM) growStack(size int) bool {
+	if size > MAX_STACK_SIZE {
+		return false
+	}
+	if size < 2*len(vm.stack) {
+		size = 2 * len(vm.stack)
+	}
+	grown := make([]object.Object, size)
+	copy(grown, vm.stack[:vm.sp])
+	vm.stack = grown
+	return true
+}
+
+func stackOverflow() *object.Error {
+	return object.NewLimitError(object.CALL_DEPTH_EXCEEDED, "stack overflow")
+}
+
+// orNull stands for slots read before having been set
+func orNull(obj object.Object) object.Object {
+	if obj == nil {
+		return object.NULL
+	}
+	return obj
+}
+
+func (vm *VM) call(arguments int) object.Object {
+	callee := vm.stack[vm.sp-1-arguments]
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, arguments)
+	case *object.Builtin:
+		result := callee.Fn(vm.stack[vm.sp-arguments : vm.sp]...)
+		vm.sp -= arguments + 1
+		if err, ok := result.(*object.Error); ok {
+			err.Trace = append(err.Trace, object.Frame{Function: callee.Name, Call: vm.callPosition()})
+			return err
+		}
+		return vm.push(result)
+	}
+	return object.NewError("not a function")
+}
+
+// undefinedGlobal resolves a global the compiler has declared without the
+// program defining it, as with the target of a failed assignment, to the
+// builtin of the same name, as the evaluator looks builtins up once globals
+// are not found
+func (vm *VM) undefinedGlobal(index int) object.Object {
+	name := vm.globalNames[index]
+	if builtin, ok := vm.builtins.LookUp(name); ok {
+		return builtin
+	}
+	return object.NewError(fmt.Sprintf("reference error: %s is not defined", name))
+}
+
+func (vm *VM) callClosure(closure *object.Closure, arguments int) object.Object {
+	parameters := closure.Fn.NumParameters
+	if arguments < parameters {
+		return object.NewError(fmt.Sprintf("type error: Expected %d arguments. Got %d",
+			parameters, arguments))
+	}
+	// Extra arguments are ignored
+	vm.sp -= arguments - parameters
+
+	if len(vm.frames) >= MAX_FRAMES {
+		return stackOverflow()
+	}
+
+	basePointer := vm.sp - parameters
+	top := basePointer + closure.Fn.NumLocals
+	if top >= len(vm.stack) && !vm.growStack(top+1) {
+		return stackOverflow()
+	}
+	// Clear the locals left over from previous calls
+	for slot := vm.sp; slot < top; slot++ {
+		vm.stack[slot] = nil
+	}
+
+	vm.frames = append(vm.frames, NewFrame(closure, basePointer))
+	vm.sp = top
+	return nil
+}
+
+func (vm *VM) pushClosure(index int, count int) object.Object {
+	function, ok := vm.constants[index].(*object.CompiledFunction)
+	if !ok {
+		return object.NewError(fmt.Sprintf("not a function: %s", vm.constants[index].Inspect()))
+	}
+
+	free := make([]object.Object, count)
+	copy(free, vm.stack[vm.sp-count:vm.sp])
+	vm.sp -= count
+
+	return vm.push(&object.Closure{Fn: function, Free: free})
+}
+
+func (vm *VM) buildHash(items []object.Object) object.Object {
+	hash := object.NewHash()
+	for index := 0; index < len(items); index += 2 {
+		key, ok := items[index].(object.Hashable)
+		if !ok {
+			return object.NewError(fmt.Sprintf("value error: unhashable type as hash key: %s",
+				items[index].Type()))
+		}
+		hash.Set(key, items[index+1])
+	}
+	return hash
+}
+
+// decides tells whether the operand of a logical jump decides the result of
+// its operator, in which case the jump is taken
+func decides(op code.Opcode, operand object.Object) bool {
+	switch op {
+	case code.OpJumpFalsy:
+		return !evaluator.IsTruthy(operand)
+	case code.OpJumpTruthy:
+		return evaluator.IsTruthy(operand)
+	}
+	return operand != object.NULL
+}
+
+// setIndex stores the value into the container, after applying the operator
+// of a compound assignment, given as 1 + its index, to the current value
+func (vm *VM) setIndex(container object.Object, index object.Object, operator int, value object.Object) object.Object {
+	if operator > 0 {
+		current := evaluator.EvalIndex(container, index)
+		if err, ok := current.(*object.Error); ok {
+			return err
+		}
+		value = evaluator.EvalInfix(code.Operators[operator-1], current, value)
+		if err, ok := value.(*object.Error); ok {
+			return err
+		}
+	}
+	return evaluator.SetIndex(container, index, value)
+}
+
+// iterate pushes the next items of the iterator, or jumps to the target once
+// there are no more
+func (vm *VM) iterate(it *iterator, frame *Frame, target int) object.Object {
+	if it.next >= len(it.values) {
+		frame.ip = target
+		return nil
+	}
+	if it.both {
+		if err, ok := vm.push(it.keys[it.next]).(*object.Error); ok {
+			return err
+		}
+	}
+	result := vm.push(it.values[it.next])
+	it.next++
+	return result
+}
+
+// callPosition locates the call instruction the current frame is running
+func (vm *VM) callPosition() token.Position {
+	frame := vm.frames[len(vm.frames)-1]
+	return frame.position(frame.ip - 1)
+}
+
+// unwind records where the error has been raised and the calls that lead to
+// it, innermost first
+func (vm *VM) unwind(err *object.Error, offset int) *object.Error {
+	if !err.Pos.IsValid() {
+		err.Pos = vm.frames[len(vm.frames)-1].position(offset)
+	}
+	for index := len(vm.frames) - 1; index > 0; index-- {
+		caller := vm.frames[index-1]
+		err.Trace = append(err.Trace, object.Frame{
+			Function: vm.frames[index].name(),
+			Call:     caller.position(caller.ip - 1),
+		})
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package vm
+
+import (
+	"node.go/compiler"
+	"node.go/lexer"
+	"node.go/object"
+	"node.go/parser"
+	"strings"
+	"testing"
+)
+
+func run(t *testing.T, input string) object.Object {
+	lex := lexer.NewWithFilename("test.ngo", input)
+	par := parser.New(lex)
+	program := par.ParseProgram()
+	if len(par.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", par.Errors())
+	}
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	return New(comp.Bytecode()).Run()
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
+	integer, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("object is not an Integer. Got %T (%+v)", obj, obj)
+	}
+	if integer.Value != expected {
+		t.Errorf("wrong value. Expected %d. Got %d", expected, integer.Value)
+	}
+}
+
+func testErrorObject(t *testing.T, obj object.Object, message string) *object.Error {
+	err, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not an Error. Got %T (%+v)", obj, obj)
+	}
+	if err.Message != message {
+		t.Errorf("wrong error message. Expected %q. Got %q", message, err.Message)
+	}
+	return err
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let adder = fn(a) { fn(b) { a + b } }; adder(2)(3)", 5},
+		{"let f = fn(a) { let g = fn(b) { fn(c) { a + b + c } }; g(2) }; f(1)(3)", 6},
+		{"let fib = fn(n) { if (n < 2) { return n } fib(n - 1) + fib(n - 2) }; fib(15)", 610},
+		{`let count = fn(n) {
+			let iter = fn(i, acc) { if (i > n) { return acc } iter(i + 1, acc + i) };
+			iter(1, 0)
+		}; count(100)`, 5050},
+		{"let f = fn(a) { a }; f(1, 2, 3)", 1},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, run(t, test.input), test.expected)
+	}
+}
+
+func TestArgumentCount(t *testing.T) {
+	testErrorObject(t, run(t, "fn(a, b) { a }(1)"), "type error: Expected 2 arguments. Got 1")
+}
+
+func TestCallingNonFunction(t *testing.T) {
+	testErrorObject(t, run(t, "let x = 1; x()"), "not a function")
+}
+
+func TestStackOverflow(t *testing.T) {
+	err := testErrorObject(t, run(t, "let f = fn(n) { f(n + 1) + 1 }; f(0)"), "stack overflow")
+	if err.Kind != object.CALL_DEPTH_EXCEEDED {
+		t.Errorf("wrong error kind. Expected %s. Got %s", object.CALL_DEPTH_EXCEEDED, err.Kind)
+	}
+}
+
+func TestErrorTrace(t *testing.T) {
+	input := `let inner = fn(x) {
+	x + true
+};
+let outer = fn() { inner(1) };
+outer()`
+
+	err := testErrorObject(t, run(t, input), "type mismatch: INTEGER + BOOLEAN")
+
+	expected := strings.Join([]string{
+		"Traceback (most recent call last):",
+		"  test.ngo:5:1, in <program>",
+		"  test.ngo:4:20, in outer",
+		"  test.ngo:2:2, in inner",
+		"ERROR: type mismatch: INTEGER + BOOLEAN",
+	}, "\n")
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback.\nExpected:\n%s\nGot:\n%s", expected, err.Traceback())
+	}
+}
+
+func TestUndefinedGlobal(t *testing.T) {
+	err := testErrorObject(t, run(t, "let f = fn() { g }; f()"), "reference error: g is not defined")
+	if err.Pos.Line != 1 || err.Pos.Column != 16 {
+		t.Errorf("wrong error position. Expected 1:16. Got %s", err.Pos)
+	}
+}
+
+func TestCapturedVariables(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let f = fn() { let n = 0; let inc = fn() { n += 1 }; inc(); inc(); n }; f()", 2},
+		{"let f = fn(n) { let g = fn() { fn() { n = n * 2 } }; g()(); n }; f(21)", 42},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c()", 2},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, run(t, test.input), test.expected)
+	}
+}
+
+func TestAssignUndefinedGlobal(t *testing.T) {
+	err := testErrorObject(t, run(t, "let f = fn() { g = 1 }; f()"), "reference error: g is not defined")
+	if err.Pos.Line != 1 || err.Pos.Column != 16 {
+		t.Errorf("wrong error position. Expected 1:16. Got %s", err.Pos)
+	}
+}